@@ -0,0 +1,70 @@
+package w1
+
+import (
+	"io/fs"
+	"strings"
+	"time"
+)
+
+// File is a sysfs-like attribute: content is read on Open, written value is stored on Close
+type File struct {
+	r     *strings.Reader
+	w     []byte
+	store func(string) error
+}
+
+// NewReader returns read-only attribute with content, terminated with newline the way kernel does
+func NewReader(content string) *File {
+	return &File{r: strings.NewReader(content + "\n")}
+}
+
+// NewWriter returns write-only attribute, store is called on Close with written value, trimmed of whitespaces
+func NewWriter(store func(string) error) *File {
+	return &File{store: store}
+}
+
+func (f *File) Read(p []byte) (int, error) {
+	if f.r == nil {
+		return 0, fs.ErrPermission
+	}
+	return f.r.Read(p)
+}
+
+func (f *File) Write(p []byte) (int, error) {
+	if f.store == nil {
+		return 0, fs.ErrPermission
+	}
+	f.w = append(f.w, p...)
+	return len(p), nil
+}
+
+func (f *File) Close() error {
+	if f.store == nil {
+		return nil
+	}
+	store := f.store
+	f.store = nil
+	return store(strings.TrimSpace(string(f.w)))
+}
+
+// ErrNotExist is returned for missing sensor, master or attribute
+func ErrNotExist(name string) error {
+	return &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+}
+
+// DirEntry is a directory listed by ReadDir: sensor, master or attribute
+type DirEntry string
+
+func (d DirEntry) Name() string               { return string(d) }
+func (d DirEntry) IsDir() bool                { return true }
+func (d DirEntry) Type() fs.FileMode          { return fs.ModeDir }
+func (d DirEntry) Info() (fs.FileInfo, error) { return dirInfo(d), nil }
+
+type dirInfo string
+
+func (d dirInfo) Name() string       { return string(d) }
+func (d dirInfo) Size() int64        { return 0 }
+func (d dirInfo) Mode() fs.FileMode  { return fs.ModeDir | 0555 }
+func (d dirInfo) ModTime() time.Time { return time.Time{} }
+func (d dirInfo) IsDir() bool        { return true }
+func (d dirInfo) Sys() any           { return nil }
@@ -0,0 +1,53 @@
+// Package w1 holds pieces shared by 1-Wire backends, which expose sensors the way kernel w1_therm does
+package w1
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Alarm thresholds range, as specified in datasheet
+const (
+	AlarmMin = -55
+	AlarmMax = 125
+)
+
+// CRC8 is Dallas/Maxim 1-Wire CRC, polynomial x^8 + x^5 + x^4 + 1
+func CRC8(buf []byte) byte {
+	crc := byte(0)
+	for _, b := range buf {
+		for i := 0; i < 8; i++ {
+			mix := (crc ^ b) & 0x01
+			crc >>= 1
+			if mix != 0 {
+				crc ^= 0x8C
+			}
+			b >>= 1
+		}
+	}
+	return crc
+}
+
+// ParseAlarms parses thresholds written to alarms attribute, e.g. "10 30".
+// If low is higher than high, they are swapped - w1_therm does it as well.
+// ok is false, when value is malformed or thresholds are out of range.
+func ParseAlarms(value string) (low, high int, ok bool) {
+	fields := strings.Fields(value)
+	if len(fields) != 2 {
+		return 0, 0, false
+	}
+	var err error
+	if low, err = strconv.Atoi(fields[0]); err != nil {
+		return 0, 0, false
+	}
+	if high, err = strconv.Atoi(fields[1]); err != nil {
+		return 0, 0, false
+	}
+	if low > high {
+		low, high = high, low
+	}
+	if low < AlarmMin || high > AlarmMax {
+		return 0, 0, false
+	}
+	return low, high, true
+}
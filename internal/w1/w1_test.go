@@ -0,0 +1,63 @@
+package w1_test
+
+import (
+	"errors"
+	"github.com/a-clap/iot/internal/w1"
+	"github.com/stretchr/testify/require"
+	"io"
+	"io/fs"
+	"testing"
+)
+
+func TestCRC8(t *testing.T) {
+	// ROM of DS18B20, last byte is CRC of previous ones
+	rom := []byte{0x28, 0xff, 0xae, 0x97, 0x93, 0x16, 0x05}
+	require.Equal(t, byte(0x00), w1.CRC8(append(rom, w1.CRC8(rom))))
+	require.Equal(t, byte(0x00), w1.CRC8(nil))
+}
+
+func TestParseAlarms(t *testing.T) {
+	tests := []struct {
+		value     string
+		low, high int
+		ok        bool
+	}{
+		{value: "10 30", low: 10, high: 30, ok: true},
+		{value: "30 10", low: 10, high: 30, ok: true},
+		{value: "-55 125", low: -55, high: 125, ok: true},
+		{value: "-56 30"},
+		{value: "10 126"},
+		{value: "10"},
+		{value: "10 30 50"},
+		{value: "a 30"},
+	}
+	for _, tt := range tests {
+		low, high, ok := w1.ParseAlarms(tt.value)
+		require.Equal(t, tt.ok, ok, tt.value)
+		require.Equal(t, []int{tt.low, tt.high}, []int{low, high}, tt.value)
+	}
+}
+
+func TestFile(t *testing.T) {
+	r := w1.NewReader("21500")
+	buf, err := io.ReadAll(r)
+	require.Nil(t, err)
+	require.Equal(t, "21500\n", string(buf))
+	_, err = r.Write([]byte("1"))
+	require.ErrorIs(t, err, fs.ErrPermission)
+	require.Nil(t, r.Close())
+
+	var stored string
+	w := w1.NewWriter(func(value string) error {
+		stored = value
+		return errors.New("rejected")
+	})
+	_, err = w.Read(make([]byte, 1))
+	require.ErrorIs(t, err, fs.ErrPermission)
+	_, err = w.Write([]byte("10 30\n"))
+	require.Nil(t, err)
+	require.NotNil(t, w.Close())
+	require.Equal(t, "10 30", stored)
+	// Value is stored only once
+	require.Nil(t, w.Close())
+}
@@ -0,0 +1,92 @@
+package ds18b20
+
+import (
+	"fmt"
+	"github.com/a-clap/iot/internal/w1"
+	"strconv"
+	"strings"
+)
+
+const attrAlarms = "alarms"
+
+// AlarmSearcher is an optional interface of Onewire, which can perform ALARM SEARCH on bus.
+// It returns ids of sensors, which had alarm condition at the last conversion.
+type AlarmSearcher interface {
+	AlarmSearch() ([]string, error)
+}
+
+// Alarms returns TL and TH thresholds, in degrees Celsius
+func (s *sensor) Alarms() (low, high int, err error) {
+	conv, err := s.readAttr(attrAlarms)
+	if err != nil {
+		return 0, 0, err
+	}
+	fields := strings.Fields(conv)
+	if len(fields) != 2 {
+		return 0, 0, fmt.Errorf("%w: unexpected alarms content %q", ErrInterface, conv)
+	}
+	if low, err = strconv.Atoi(fields[0]); err != nil {
+		return 0, 0, fmt.Errorf("%w: %v", ErrInterface, err)
+	}
+	if high, err = strconv.Atoi(fields[1]); err != nil {
+		return 0, 0, fmt.Errorf("%w: %v", ErrInterface, err)
+	}
+	return low, high, nil
+}
+
+// SetAlarms writes TL and TH thresholds, in degrees Celsius, to sensor RAM.
+// Sensor is in alarm condition, when its temperature is lower or equal to low, or higher or equal to high.
+func (s *sensor) SetAlarms(low, high int) error {
+	if low > high || low < w1.AlarmMin || high > w1.AlarmMax {
+		return fmt.Errorf("%w: low: %v, high: %v", ErrAlarmRange, low, high)
+	}
+	return s.writeAttr(attrAlarms, fmt.Sprintf("%d %d", low, high))
+}
+
+// alarming checks alarm condition the way sensor does - only integer part of temperature is compared
+func (s *sensor) alarming() (bool, error) {
+	low, high, err := s.Alarms()
+	if err != nil {
+		return false, err
+	}
+	// Sensor knows nothing about calibration
+	tmp, err := s.RawValue()
+	if err != nil {
+		return false, err
+	}
+	integer := int(tmp / 1000)
+	if tmp < 0 && tmp%1000 != 0 {
+		integer--
+	}
+	return integer <= low || integer >= high, nil
+}
+
+// AlarmingSensors returns ids of sensors, which are in alarm condition.
+// If Onewire implements AlarmSearcher, single ALARM SEARCH is done on bus.
+// Otherwise, thresholds and temperature of each sensor are read and compared - single conversion per sensor.
+// Sensors, which can't be read (e.g. disconnected), are skipped.
+func (h *Handler) AlarmingSensors() ([]string, error) {
+	if searcher, ok := h.o.(AlarmSearcher); ok {
+		ids, err := searcher.AlarmSearch()
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInterface, err)
+		}
+		return ids, nil
+	}
+
+	ids, err := h.readIDs()
+	if err != nil {
+		return nil, err
+	}
+	var alarming []string
+	for _, id := range ids {
+		s, err := h.attachSensor(id)
+		if err != nil {
+			continue
+		}
+		if alarm, err := s.alarming(); err == nil && alarm {
+			alarming = append(alarming, id)
+		}
+	}
+	return alarming, nil
+}
@@ -0,0 +1,110 @@
+package ds18b20_test
+
+import (
+	"errors"
+	"github.com/a-clap/iot/pkg/ds18b20"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestSensor_Alarms(t *testing.T) {
+	af := afero.Afero{Fs: afero.NewMemMapFs()}
+	defer func() { _ = af.RemoveAll("") }()
+	id := "28-05169397aeff"
+	require.Nil(t, af.WriteFile(id+"/temperature", []byte("21500\n"), 0777))
+	require.Nil(t, af.WriteFile(id+"/alarms", []byte("-10 50\n"), 0777))
+
+	s, err := ds18b20.New(&iAfero{path: "", a: afero.NewIOFS(af)}).NewSensor(id)
+	require.Nil(t, err)
+
+	low, high, err := s.Alarms()
+	require.Nil(t, err)
+	require.Equal(t, -10, low)
+	require.Equal(t, 50, high)
+
+	require.Nil(t, s.SetAlarms(-55, 125))
+	buf, err := af.ReadFile(id + "/alarms")
+	require.Nil(t, err)
+	require.Equal(t, "-55 125", string(buf))
+
+	low, high, err = s.Alarms()
+	require.Nil(t, err)
+	require.Equal(t, -55, low)
+	require.Equal(t, 125, high)
+
+	for _, wrong := range [][2]int{{-56, 10}, {10, 126}, {20, 10}} {
+		require.ErrorIs(t, s.SetAlarms(wrong[0], wrong[1]), ds18b20.ErrAlarmRange)
+	}
+
+	require.Nil(t, af.WriteFile(id+"/alarms", []byte("10\n"), 0777))
+	_, _, err = s.Alarms()
+	require.ErrorIs(t, err, ds18b20.ErrInterface)
+}
+
+// iAlarm is Onewire able to do ALARM SEARCH
+type iAlarm struct {
+	*iAfero
+	alarming []string
+	err      error
+}
+
+func (i *iAlarm) AlarmSearch() ([]string, error) {
+	return i.alarming, i.err
+}
+
+var _ ds18b20.AlarmSearcher = &iAlarm{}
+
+func TestHandler_AlarmingSensors(t *testing.T) {
+	af := afero.Afero{Fs: afero.NewMemMapFs()}
+	defer func() { _ = af.RemoveAll("") }()
+
+	sensors := []struct {
+		id, temperature, alarms string
+		alarming                bool
+	}{
+		{id: "28-000000000001", temperature: "21500", alarms: "10 30", alarming: false},
+		{id: "28-000000000002", temperature: "30000", alarms: "10 30", alarming: true},
+		{id: "28-000000000003", temperature: "10999", alarms: "10 30", alarming: true},
+		{id: "28-000000000004", temperature: "-9500", alarms: "-10 30", alarming: true},
+		{id: "28-000000000005", temperature: "-9000", alarms: "-10 30", alarming: false},
+		{id: "28-000000000006", temperature: "29999", alarms: "10 30", alarming: false},
+	}
+	var expected []string
+	for _, s := range sensors {
+		require.Nil(t, af.WriteFile("bus/"+s.id+"/temperature", []byte(s.temperature+"\n"), 0777))
+		require.Nil(t, af.WriteFile("bus/"+s.id+"/alarms", []byte(s.alarms+"\n"), 0777))
+		if s.alarming {
+			expected = append(expected, s.id)
+		}
+	}
+
+	o := &iAfero{path: "bus", a: afero.NewIOFS(af)}
+	t.Run("compare thresholds", func(t *testing.T) {
+		b := &iBulk{iAfero: o}
+		ids, err := ds18b20.New(b).AlarmingSensors()
+		require.Nil(t, err)
+		require.Equal(t, expected, ids)
+		// Single conversion per sensor
+		require.Equal(t, len(sensors), b.early)
+	})
+
+	t.Run("alarm search", func(t *testing.T) {
+		ids, err := ds18b20.New(&iAlarm{iAfero: o, alarming: []string{sensors[0].id}}).AlarmingSensors()
+		require.Nil(t, err)
+		require.Equal(t, []string{sensors[0].id}, ids)
+
+		_, err = ds18b20.New(&iAlarm{iAfero: o, err: errors.New("bus")}).AlarmingSensors()
+		require.ErrorIs(t, err, ds18b20.ErrInterface)
+	})
+
+	t.Run("unreadable sensors are skipped", func(t *testing.T) {
+		// Without alarms
+		require.Nil(t, af.WriteFile("bus/28-000000000007/temperature", []byte("0\n"), 0777))
+		// Disconnected
+		require.Nil(t, af.WriteFile("bus/28-000000000008/alarms", []byte("10 30\n"), 0777))
+		ids, err := ds18b20.New(o).AlarmingSensors()
+		require.Nil(t, err)
+		require.Equal(t, expected, ids)
+	})
+}
@@ -0,0 +1,135 @@
+package ds18b20
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	attrBulkRead   = "therm_bulk_read"
+	bulkTrigger    = "trigger"
+	bulkInProgress = -1
+)
+
+var (
+	// bulkTimeout is the longest time to wait for conversion, with a margin for slow bus
+	bulkTimeout = 2 * Resolution12Bit.ConversionTime()
+	// bulkPollInterval is how often master is asked, whether conversion is finished
+	bulkPollInterval = 10 * time.Millisecond
+)
+
+// BulkRead starts conversion on every sensor at once, waits until it is finished and then reads all sensors.
+// It takes as long as the slowest sensor conversion, instead of sum of them.
+// Errors of particular sensors are kept in their Readings.
+func (h *Handler) BulkRead() ([]Readings, error) {
+	ids, err := h.readIDs()
+	if err != nil {
+		return nil, err
+	}
+	targets := make([]target, 0, len(ids))
+	for _, id := range ids {
+		// Reading sensor here would start conversion, which is what bulk read avoids
+		s, err := h.attachSensor(id)
+		targets = append(targets, target{id: id, s: s, err: err})
+	}
+
+	attrs, err := h.bulkMasters()
+	if err != nil {
+		return nil, err
+	}
+	if err := h.bulkConvert(attrs); err != nil {
+		return nil, err
+	}
+	return readAll(targets), nil
+}
+
+// bulkMasters returns paths to therm_bulk_read attribute of each master, which exposes it
+func (h *Handler) bulkMasters() ([]string, error) {
+	names, err := h.masterNames()
+	if err != nil {
+		return nil, err
+	}
+	var attrs []string
+	for _, name := range names {
+		attr := h.o.Path() + "/" + name + "/" + attrBulkRead
+		if _, err := readAttr(h.o, attr); err == nil {
+			attrs = append(attrs, attr)
+		}
+	}
+	return attrs, nil
+}
+
+// bulkConvert triggers conversion on masters with therm_bulk_read attrs and waits until all of them are finished.
+// Masters aren't listed here, so repeated conversions don't search bus.
+func (h *Handler) bulkConvert(attrs []string) error {
+	if len(attrs) == 0 {
+		return ErrBulkReadUnsupported
+	}
+	for _, attr := range attrs {
+		if err := writeAttr(h.o, attr, bulkTrigger); err != nil {
+			return err
+		}
+	}
+
+	deadline := time.Now().Add(bulkTimeout)
+	for _, attr := range attrs {
+		for {
+			done, err := bulkFinished(h.o, attr)
+			if err != nil {
+				return err
+			}
+			if done {
+				break
+			}
+			if time.Now().After(deadline) {
+				return ErrBulkTimeout
+			}
+			<-time.After(bulkPollInterval)
+		}
+	}
+	return nil
+}
+
+// bulkFinished reads therm_bulk_read: -1 means conversion in progress,
+// 1 - conversion done, but not every value was read yet, 0 - nothing pending
+func bulkFinished(o opener, attr string) (bool, error) {
+	conv, err := readAttr(o, attr)
+	if err != nil {
+		return false, err
+	}
+	state, err := strconv.Atoi(strings.TrimSpace(conv))
+	if err != nil {
+		return false, fmt.Errorf("%w: %v", ErrInterface, err)
+	}
+	return state != bulkInProgress, nil
+}
+
+// target is a sensor read in batch, err is kept if sensor couldn't be created
+type target struct {
+	id  string
+	s   *sensor
+	err error
+}
+
+// readAll reads every sensor, all readings share the same timestamp
+func readAll(targets []target) []Readings {
+	timestamp := time.Now()
+	batch := make([]Readings, len(targets))
+	for i, t := range targets {
+		if t.err != nil {
+			batch[i] = readings{id: t.id, timestamp: timestamp, err: t.err}
+			continue
+		}
+		raw, tmp, err := t.s.measure()
+		batch[i] = readings{
+			id:          t.id,
+			raw:         raw,
+			temperature: tmp,
+			timestamp:   timestamp,
+			err:         err,
+		}
+	}
+	return batch
+}
@@ -0,0 +1,179 @@
+package ds18b20_test
+
+import (
+	"context"
+	"github.com/a-clap/iot/pkg/ds18b20"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+	"io/fs"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// iBulk emulates therm_bulk_read attribute of master, other files are served by afero
+type iBulk struct {
+	*iAfero
+	mtx        sync.Mutex
+	converting int
+	triggers   int
+	// busy is a number of reads reporting conversion in progress, after trigger
+	busy int
+	// early is a number of temperature reads before the first trigger, each of them is a separate conversion
+	early int
+	// listings is a number of root directory reads, each of them is a bus search on real master
+	listings int
+}
+
+type bulkFile struct {
+	seqFile
+	b *iBulk
+}
+
+func (b bulkFile) Write(p []byte) (int, error) {
+	if string(p) == "trigger" {
+		b.b.mtx.Lock()
+		b.b.triggers++
+		b.b.converting = b.b.busy
+		b.b.mtx.Unlock()
+	}
+	return len(p), nil
+}
+
+func (b *iBulk) Open(name string) (ds18b20.File, error) {
+	b.mtx.Lock()
+	defer b.mtx.Unlock()
+	if !strings.HasSuffix(name, "/therm_bulk_read") {
+		if strings.HasSuffix(name, "/temperature") && b.triggers == 0 {
+			b.early++
+		}
+		return b.iAfero.Open(name)
+	}
+	state := 0
+	if b.converting > 0 {
+		b.converting--
+		state = -1
+	} else if b.triggers > 0 {
+		state = 1
+	}
+	return bulkFile{seqFile: seqFile{strings.NewReader(strconv.Itoa(state) + "\n")}, b: b}, nil
+}
+
+func (b *iBulk) OpenFile(name string, flag int, perm fs.FileMode) (ds18b20.File, error) {
+	if !strings.HasSuffix(name, "/therm_bulk_read") {
+		return b.iAfero.OpenFile(name, flag, perm)
+	}
+	return bulkFile{seqFile: seqFile{strings.NewReader("")}, b: b}, nil
+}
+
+func (b *iBulk) ReadDir(dirname string) ([]fs.DirEntry, error) {
+	b.mtx.Lock()
+	if dirname == b.Path() {
+		b.listings++
+	}
+	b.mtx.Unlock()
+	return b.iAfero.ReadDir(dirname)
+}
+
+func (b *iBulk) listed() int {
+	b.mtx.Lock()
+	defer b.mtx.Unlock()
+	return b.listings
+}
+
+func (b *iBulk) triggered() int {
+	b.mtx.Lock()
+	defer b.mtx.Unlock()
+	return b.triggers
+}
+
+func prepareBulkBus(t *testing.T, withBulk bool) (afero.Afero, []string) {
+	af := afero.Afero{Fs: afero.NewMemMapFs()}
+	ids := []string{"28-05169397aeff", "28-0516939fffff"}
+	require.Nil(t, af.WriteFile("bus/"+ids[0]+"/temperature", []byte("12345\n"), 0777))
+	require.Nil(t, af.WriteFile("bus/"+ids[1]+"/temperature", []byte("-1250\n"), 0777))
+	require.Nil(t, af.Mkdir("bus/w1_bus_master1", 0777))
+	if withBulk {
+		require.Nil(t, af.WriteFile("bus/w1_bus_master1/therm_bulk_read", []byte("0\n"), 0777))
+	}
+	return af, ids
+}
+
+func TestHandler_BulkRead(t *testing.T) {
+	af, ids := prepareBulkBus(t, true)
+	defer func() { _ = af.RemoveAll("") }()
+
+	o := &iBulk{iAfero: &iAfero{path: "bus", a: afero.NewIOFS(af)}, busy: 3}
+	h := ds18b20.New(o)
+
+	batch, err := h.BulkRead()
+	require.Nil(t, err)
+	require.Equal(t, 1, o.triggered())
+	require.Len(t, batch, len(ids))
+
+	expected := []ds18b20.Millicelsius{12345, -1250}
+	_, stamp, _ := batch[0].Value()
+	for i, r := range batch {
+		require.Equal(t, ids[i], r.ID())
+		tmp, rstamp, err := r.Value()
+		require.Nil(t, err)
+		require.Equal(t, expected[i], tmp)
+		require.Equal(t, stamp, rstamp)
+	}
+	// Conversion in progress reads were consumed, sensors weren't read before trigger
+	o.mtx.Lock()
+	require.Equal(t, 0, o.converting)
+	require.Zero(t, o.early)
+	o.mtx.Unlock()
+}
+
+func TestHandler_BulkReadUnsupported(t *testing.T) {
+	af, _ := prepareBulkBus(t, false)
+	defer func() { _ = af.RemoveAll("") }()
+
+	h := ds18b20.New(&iAfero{path: "bus", a: afero.NewIOFS(af)})
+	batch, err := h.BulkRead()
+	require.Nil(t, batch)
+	require.ErrorIs(t, err, ds18b20.ErrBulkReadUnsupported)
+}
+
+func TestHandler_BulkReadTimeout(t *testing.T) {
+	af, _ := prepareBulkBus(t, true)
+	defer func() { _ = af.RemoveAll("") }()
+
+	o := &iBulk{iAfero: &iAfero{path: "bus", a: afero.NewIOFS(af)}, busy: 1 << 20}
+	batch, err := ds18b20.New(o).BulkRead()
+	require.Nil(t, batch)
+	require.ErrorIs(t, err, ds18b20.ErrBulkTimeout)
+}
+
+func TestHandler_PollUsesBulkRead(t *testing.T) {
+	af, ids := prepareBulkBus(t, true)
+	defer func() { _ = af.RemoveAll("") }()
+
+	o := &iBulk{iAfero: &iAfero{path: "bus", a: afero.NewIOFS(af)}, busy: 1}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	data, err := ds18b20.New(o).Poll(ctx, nil, time.Millisecond)
+	require.Nil(t, err)
+
+	listed := 0
+	for i := 1; i <= 3; i++ {
+		select {
+		case batch := <-data:
+			require.Len(t, batch, len(ids))
+			// Next sweep could have been already started
+			require.GreaterOrEqual(t, o.triggered(), i)
+		case <-time.After(time.Second):
+			require.Fail(t, "waiting for readings too long")
+		}
+		// Masters are listed once, sweeps don't search bus again
+		if i == 1 {
+			listed = o.listed()
+		}
+		require.Equal(t, listed, o.listed())
+	}
+}
@@ -0,0 +1,66 @@
+package ds18b20
+
+// Calibration corrects temperature read from sensor.
+// If two reference points are given (RawLow differs from RawHigh), reading is linearly mapped,
+// so that RawLow becomes RefLow and RawHigh becomes RefHigh - e.g. ice bath and boiling water.
+// Otherwise, Offset is added to reading.
+// Zero value is a valid calibration, which doesn't change anything.
+type Calibration struct {
+	Offset  Millicelsius `json:"offset"`
+	RawLow  Millicelsius `json:"raw_low"`
+	RefLow  Millicelsius `json:"ref_low"`
+	RawHigh Millicelsius `json:"raw_high"`
+	RefHigh Millicelsius `json:"ref_high"`
+}
+
+// Calibrations maps sensor id to its calibration, can be passed to Handler to calibrate all sensors
+type Calibrations map[string]Calibration
+
+// NewOffsetCalibration creates calibration, which moves every reading by offset
+func NewOffsetCalibration(offset Millicelsius) Calibration {
+	return Calibration{Offset: offset}
+}
+
+// NewTwoPointCalibration creates calibration based on two reference points
+func NewTwoPointCalibration(rawLow, refLow, rawHigh, refHigh Millicelsius) Calibration {
+	return Calibration{
+		RawLow:  rawLow,
+		RefLow:  refLow,
+		RawHigh: rawHigh,
+		RefHigh: refHigh,
+	}
+}
+
+func (c Calibration) twoPoint() bool {
+	return c.RawLow != c.RawHigh
+}
+
+func (c Calibration) apply(raw Millicelsius) Millicelsius {
+	if !c.twoPoint() {
+		return raw + c.Offset
+	}
+	// int64 to avoid overflow, result is rounded to nearest
+	num := int64(raw-c.RawLow) * int64(c.RefHigh-c.RefLow)
+	den := int64(c.RawHigh - c.RawLow)
+	if den < 0 {
+		num, den = -num, -den
+	}
+	half := den / 2
+	if num < 0 {
+		half = -half
+	}
+	return c.RefLow + Millicelsius((num+half)/den)
+}
+
+func (s *sensor) Calibration() Calibration {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	return s.calibration
+}
+
+// SetCalibration sets calibration applied to every reading, including Poll
+func (s *sensor) SetCalibration(c Calibration) {
+	s.mtx.Lock()
+	s.calibration = c
+	s.mtx.Unlock()
+}
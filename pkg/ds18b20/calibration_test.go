@@ -0,0 +1,157 @@
+package ds18b20_test
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/a-clap/iot/pkg/ds18b20"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestSensor_Calibration(t *testing.T) {
+	af := afero.Afero{Fs: afero.NewMemMapFs()}
+	defer func() { _ = af.RemoveAll("") }()
+	id := "28-05169397aeff"
+	filePath := id + "/temperature"
+	require.Nil(t, af.WriteFile(filePath, []byte("0\n"), 0777))
+
+	s, err := ds18b20.New(&iAfero{path: "", a: afero.NewIOFS(af)}).NewSensor(id)
+	require.Nil(t, err)
+	require.Equal(t, ds18b20.Calibration{}, s.Calibration())
+
+	tests := []struct {
+		name        string
+		calibration ds18b20.Calibration
+		raw         string
+		expected    ds18b20.Millicelsius
+	}{
+		{
+			name:        "no calibration",
+			calibration: ds18b20.Calibration{},
+			raw:         "21500",
+			expected:    21500,
+		},
+		{
+			name:        "positive offset",
+			calibration: ds18b20.NewOffsetCalibration(400),
+			raw:         "21500",
+			expected:    21900,
+		},
+		{
+			name:        "negative offset",
+			calibration: ds18b20.NewOffsetCalibration(-400),
+			raw:         "250",
+			expected:    -150,
+		},
+		{
+			name:        "two point - low reference",
+			calibration: ds18b20.NewTwoPointCalibration(400, 0, 99400, 100000),
+			raw:         "400",
+			expected:    0,
+		},
+		{
+			name:        "two point - high reference",
+			calibration: ds18b20.NewTwoPointCalibration(400, 0, 99400, 100000),
+			raw:         "99400",
+			expected:    100000,
+		},
+		{
+			name:        "two point - in between",
+			calibration: ds18b20.NewTwoPointCalibration(400, 0, 99400, 100000),
+			raw:         "49900",
+			expected:    50000,
+		},
+		{
+			name:        "two point - below range",
+			calibration: ds18b20.NewTwoPointCalibration(400, 0, 99400, 100000),
+			raw:         "-9500",
+			expected:    -10000,
+		},
+		{
+			name:        "two point - points swapped",
+			calibration: ds18b20.NewTwoPointCalibration(99400, 100000, 400, 0),
+			raw:         "49900",
+			expected:    50000,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Nil(t, af.WriteFile(filePath, []byte(tt.raw+"\n"), 0777))
+			s.SetCalibration(tt.calibration)
+			require.Equal(t, tt.calibration, s.Calibration())
+
+			tmp, err := s.Value()
+			require.Nil(t, err)
+			require.Equal(t, tt.expected, tmp)
+
+			str, err := s.Temperature()
+			require.Nil(t, err)
+			require.Equal(t, tt.expected.String(), str)
+
+			raw, err := s.RawValue()
+			require.Nil(t, err)
+			require.Equal(t, tt.raw, strconv.Itoa(int(raw)))
+		})
+	}
+}
+
+func TestCalibration_JSON(t *testing.T) {
+	c := ds18b20.Calibrations{
+		"28-05169397aeff": ds18b20.NewOffsetCalibration(-400),
+		"28-0516939fffff": ds18b20.NewTwoPointCalibration(400, 0, 99400, 100000),
+	}
+	buf, err := json.Marshal(c)
+	require.Nil(t, err)
+
+	var got ds18b20.Calibrations
+	require.Nil(t, json.Unmarshal(buf, &got))
+	require.Equal(t, c, got)
+}
+
+func TestHandler_Calibrations(t *testing.T) {
+	af := afero.Afero{Fs: afero.NewMemMapFs()}
+	defer func() { _ = af.RemoveAll("") }()
+	ids := []string{"28-05169397aeff", "28-0516939fffff"}
+	for _, id := range ids {
+		require.Nil(t, af.WriteFile("bus/"+id+"/temperature", []byte("21500\n"), 0777))
+	}
+	calibrations := ds18b20.Calibrations{
+		ids[0]: ds18b20.NewOffsetCalibration(-500),
+	}
+	h := ds18b20.New(&iAfero{path: "bus", a: afero.NewIOFS(af)}, calibrations)
+
+	s, err := h.NewSensor(ids[0])
+	require.Nil(t, err)
+	require.Equal(t, calibrations[ids[0]], s.Calibration())
+
+	// Calibration passed to NewSensor wins
+	s, err = h.NewSensor(ids[0], ds18b20.NewOffsetCalibration(100))
+	require.Nil(t, err)
+	require.Equal(t, ds18b20.NewOffsetCalibration(100), s.Calibration())
+
+	s, err = h.NewSensor(ids[1])
+	require.Nil(t, err)
+	require.Equal(t, ds18b20.Calibration{}, s.Calibration())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	data, err := h.Poll(ctx, nil, time.Millisecond)
+	require.Nil(t, err)
+
+	select {
+	case batch := <-data:
+		require.Len(t, batch, 2)
+		expected := []ds18b20.Millicelsius{21000, 21500}
+		for i, r := range batch {
+			tmp, _, err := r.Value()
+			require.Nil(t, err)
+			require.Equal(t, expected[i], tmp)
+			require.EqualValues(t, 21500, r.Raw())
+		}
+	case <-time.After(time.Second):
+		require.Fail(t, "waiting for readings too long")
+	}
+}
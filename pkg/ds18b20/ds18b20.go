@@ -5,40 +5,65 @@ import (
 	"fmt"
 	"io"
 	"io/fs"
+	"sync"
 )
 
 var (
-	ErrInterface      = errors.New("interface")
-	ErrAlreadyPolling = errors.New("sensor is already polling")
+	ErrInterface           = errors.New("interface")
+	ErrAlreadyPolling      = errors.New("sensor is already polling")
+	ErrWrongInterval       = errors.New("interval must be positive")
+	ErrIntervalShort       = errors.New("poll interval shorter than conversion time")
+	ErrResolution          = errors.New("resolution out of range")
+	ErrCRC                 = errors.New("crc mismatch")
+	ErrPowerOnReset        = errors.New("sensor reports power-on reset value")
+	ErrDisconnected        = errors.New("sensor seems disconnected")
+	ErrUnsupportedFamily   = errors.New("unsupported family")
+	ErrNotSupported        = errors.New("not supported by sensor family")
+	ErrThermocoupleFault   = errors.New("thermocouple fault")
+	ErrBulkReadUnsupported = errors.New("bulk read not supported by bus master")
+	ErrBulkTimeout         = errors.New("bulk conversion timeout")
+	ErrAlarmRange          = errors.New("alarm thresholds out of range")
 )
 
 type File interface {
 	io.Reader
+	io.Writer
+	io.Closer
 }
 
 type Onewire interface {
 	Path() string
 	ReadDir(dirname string) ([]fs.DirEntry, error)
 	Open(name string) (File, error)
+	OpenFile(name string, flag int, perm fs.FileMode) (File, error)
 }
 
 type Handler struct {
-	ids []string
-	o   Onewire
+	ids  []string
+	o    Onewire
+	args []any
+	mtx  sync.Mutex
+	// masters maps sensor id to name of its master, filled whenever masters are listed
+	masters map[string]string
 }
 
-func New(o Onewire) *Handler {
+// New creates Handler on top of Onewire. Args are passed to every sensor created by Handler.
+func New(o Onewire, args ...any) *Handler {
 	return &Handler{
-		o:   o,
-		ids: nil,
+		o:    o,
+		ids:  nil,
+		args: args,
 	}
 }
 
-func NewDefault() *Handler {
-	return &Handler{
-		o:   &onewire{},
-		ids: nil,
-	}
+// NewDefault creates Handler using kernel w1 sysfs interface at DefaultPath
+func NewDefault(args ...any) *Handler {
+	return NewSysfs(DefaultPath, args...)
+}
+
+// NewSysfs creates Handler using kernel w1 sysfs interface mounted at root
+func NewSysfs(root string, args ...any) *Handler {
+	return New(&onewire{root: root}, args...)
 }
 
 func (h *Handler) IDs() ([]string, error) {
@@ -46,27 +71,57 @@ func (h *Handler) IDs() ([]string, error) {
 	return h.ids, err
 }
 
-func (h *Handler) NewSensor(id string) (Sensor, error) {
+// NewSensor creates sensor with id. Args override the ones passed to Handler.
+func (h *Handler) NewSensor(id string, args ...any) (Sensor, error) {
+	s, err := h.newSensor(id, args...)
+	if err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func (h *Handler) newSensor(id string, args ...any) (*sensor, error) {
 	// delegate creation of sensor to newSensor
-	s, err := newSensor(h.o, id, h.o.Path())
+	s, err := newSensor(h.o, id, h.o.Path(), append(append([]any{}, h.args...), args...)...)
+	if err != nil {
+		return nil, err
+	}
+	// Master is looked up only when asked for, listing masters searches bus on some of them
+	s.masterOf = h.masterOf
+	return s, nil
+}
+
+// attachSensor creates sensor with id, without reading it
+func (h *Handler) attachSensor(id string) (*sensor, error) {
+	s, err := attachSensor(h.o, id, h.o.Path(), h.args...)
 	if err != nil {
 		return nil, err
 	}
+	s.masterOf = h.masterOf
 	return s, nil
 }
 
 func (h *Handler) updateIDs() error {
+	ids, err := h.readIDs()
+	if err != nil {
+		return err
+	}
+	h.ids = ids
+	return nil
+}
+
+// readIDs returns fresh snapshot of ids available on bus, doesn't touch h.ids
+func (h *Handler) readIDs() ([]string, error) {
 	files, err := h.o.ReadDir(h.o.Path())
 	if err != nil {
-		return fmt.Errorf("%w: %v", ErrInterface, err)
+		return nil, fmt.Errorf("%w: %v", ErrInterface, err)
 	}
+	var ids []string
 	for _, maybeOnewire := range files {
-		if name := maybeOnewire.Name(); len(name) > 0 {
-			// Onewire id starts with digit
-			if name[0] >= '0' && name[0] <= '9' {
-				h.ids = append(h.ids, name)
-			}
+		// Bus contains also masters and other devices, take only known thermometers
+		if _, err := parseFamily(maybeOnewire.Name()); err == nil {
+			ids = append(ids, maybeOnewire.Name())
 		}
 	}
-	return nil
+	return ids, nil
 }
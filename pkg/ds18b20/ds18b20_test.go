@@ -1,6 +1,7 @@
 package ds18b20_test
 
 import (
+	"context"
 	"fmt"
 	"github.com/a-clap/iot/pkg/ds18b20"
 	"github.com/spf13/afero"
@@ -8,6 +9,7 @@ import (
 	"io/fs"
 	"os"
 	"path/filepath"
+	"strconv"
 	"testing"
 	"time"
 )
@@ -19,6 +21,10 @@ func (i *iError) Open(string) (ds18b20.File, error) {
 	panic("shouldn't be used")
 }
 
+func (i *iError) OpenFile(string, int, fs.FileMode) (ds18b20.File, error) {
+	panic("shouldn't be used")
+}
+
 func (i *iError) ReadDir(string) ([]fs.DirEntry, error) {
 	return nil, fmt.Errorf("interfaceError")
 }
@@ -40,7 +46,14 @@ func (i *iAfero) Open(name string) (ds18b20.File, error) {
 	if len(name) > 1 && name[0] == '/' {
 		name = name[1:]
 	}
-	return i.a.Open(name)
+	return i.a.Fs.Open(name)
+}
+
+func (i *iAfero) OpenFile(name string, flag int, perm fs.FileMode) (ds18b20.File, error) {
+	if len(name) > 1 && name[0] == '/' {
+		name = name[1:]
+	}
+	return i.a.Fs.OpenFile(name, flag, perm)
 }
 
 func (i *iAfero) Path() string {
@@ -70,9 +83,10 @@ func TestHandler_Devices(t *testing.T) {
 	require.Nil(t, err)
 
 	multipleDevicesPath := "/onewire/multiple_devices"
-	multipleDevices := []string{"1234", "182-2313123", "999996696"}
+	multipleDevices := []string{"10-000802b5a3ce", "22-000003c3a8d6", "28-05169397aeff", "3b-0000001859e2", "42-00000022c8a1"}
+	otherDevices := []string{"01-000012345678", "1234", "182-2313123", "999996696", "w1_bus_master1"}
 	require.Nil(t, af.Mkdir(multipleDevicesPath, 0777))
-	for _, device := range multipleDevices {
+	for _, device := range append(multipleDevices, otherDevices...) {
 		_, err = af.Create(multipleDevicesPath + "/" + device)
 	}
 
@@ -155,7 +169,7 @@ func TestHandler_NewSensor(t *testing.T) {
 	sensorDoesntexist := "not_exist"
 
 	sensorIDWithoutTemperaturePath := "/exist"
-	sensorIDWithoutTemperature := "81-12131"
+	sensorIDWithoutTemperature := "28-12131"
 	p := filepath.Join(sensorIDWithoutTemperaturePath, sensorIDWithoutTemperature)
 	require.Nil(t, af.Mkdir(p, 0777))
 
@@ -186,10 +200,20 @@ func TestHandler_NewSensor(t *testing.T) {
 				path: sensorDoesntexist,
 				a:    afero.NewIOFS(af),
 			},
-			argsId:  "blabla",
+			argsId:  "28-000000000000",
 			wantErr: true,
 			errType: os.ErrNotExist,
 		},
+		{
+			name: "unsupported family",
+			o: &iAfero{
+				path: sensorGoodPath,
+				a:    afero.NewIOFS(af),
+			},
+			argsId:  "81-12131",
+			wantErr: true,
+			errType: ds18b20.ErrUnsupportedFamily,
+		},
 		{
 			name: "temperature file doesn't exist",
 			o: &iAfero{
@@ -236,9 +260,7 @@ func TestHandler_SensorTemperature(t *testing.T) {
 	p := filepath.Join("wire", id)
 	require.Nil(t, af.Mkdir(p, 0777))
 	filePath := filepath.Join(p, "temperature")
-	_, err := af.Create(filePath)
-	require.Nil(t, err)
-	err = af.Chmod(filePath, 0777)
+	err := af.WriteFile(filePath, []byte("0\n"), 0777)
 	require.Nil(t, err)
 	// Prepare interface
 	o := &iAfero{
@@ -278,11 +300,31 @@ func TestHandler_SensorTemperature(t *testing.T) {
 			write:    "38\n",
 			expected: "0.038",
 		},
+		{
+			write:    "0\n",
+			expected: "0.000",
+		},
+		{
+			write:    "-1250\n",
+			expected: "-1.250",
+		},
+		{
+			write:    "-125\n",
+			expected: "-0.125",
+		},
+		{
+			write:    "-5\n",
+			expected: "-0.005",
+		},
+		{
+			write:    "-55000\n",
+			expected: "-55.000",
+		},
 	}
 
 	t.Run("proper conversions", func(t *testing.T) {
 		for _, test := range tests {
-			f, err := af.OpenFile(filePath, os.O_WRONLY, 0777)
+			f, err := af.OpenFile(filePath, os.O_WRONLY|os.O_TRUNC, 0777)
 			require.Nil(t, err)
 			n, err := f.Write([]byte(test.write))
 			require.Equal(t, len(test.write), n)
@@ -293,15 +335,47 @@ func TestHandler_SensorTemperature(t *testing.T) {
 			require.Nil(t, err)
 
 			require.EqualValues(t, test.expected, r)
+
+			value, err := s.Value()
+			require.Nil(t, err)
+			require.EqualValues(t, test.expected, value.String())
 		}
 	})
+
+	t.Run("garbage in file", func(t *testing.T) {
+		require.Nil(t, af.WriteFile(filePath, []byte("12a45\n"), 0777))
+		_, err := s.Temperature()
+		require.ErrorIs(t, err, ds18b20.ErrInterface)
+		_, err = s.Value()
+		require.ErrorIs(t, err, ds18b20.ErrInterface)
+	})
+}
+
+func TestMillicelsius(t *testing.T) {
+	tests := []struct {
+		value   ds18b20.Millicelsius
+		celsius float32
+		str     string
+	}{
+		{value: 0, celsius: 0, str: "0.000"},
+		{value: 1, celsius: 0.001, str: "0.001"},
+		{value: 125000, celsius: 125, str: "125.000"},
+		{value: 21375, celsius: 21.375, str: "21.375"},
+		{value: -1250, celsius: -1.25, str: "-1.250"},
+		{value: -125, celsius: -0.125, str: "-0.125"},
+		{value: -55000, celsius: -55, str: "-55.000"},
+	}
+	for _, tt := range tests {
+		require.InDelta(t, tt.celsius, tt.value.Celsius(), 0.0001)
+		require.Equal(t, tt.str, tt.value.String())
+	}
 }
 
 func TestSensor_PollTwice(t *testing.T) {
 	af := afero.Afero{Fs: afero.NewMemMapFs()}
 	defer func() { _ = af.RemoveAll("") }()
 	// Prepare sensor
-	expectedID := "281ab"
+	expectedID := "28-0000000281ab"
 	tmp := "12345"
 	require.Nil(t, af.Mkdir(expectedID, 0777))
 	f, err := af.Create(expectedID + "/temperature")
@@ -320,10 +394,10 @@ func TestSensor_PollTwice(t *testing.T) {
 	h := ds18b20.New(o)
 	s, _ := h.NewSensor(expectedID)
 
-	errs := s.Poll(readings, interval)
+	errs := s.Poll(context.Background(), readings, interval)
 	require.Nil(t, errs)
 
-	err = s.Poll(readings, interval)
+	err = s.Poll(context.Background(), readings, interval)
 	require.ErrorIs(t, err, ds18b20.ErrAlreadyPolling)
 
 	wait := make(chan struct{})
@@ -346,7 +420,7 @@ func TestHandler_Poll_IntervalsTemperatureUpdate(t *testing.T) {
 	af := afero.Afero{Fs: afero.NewMemMapFs()}
 	defer func() { _ = af.RemoveAll("") }()
 	// Prepare sensor
-	expectedID := "281ab"
+	expectedID := "28-0000000281ab"
 	expectedTemp := "12.345"
 	tmp := "12345"
 	require.Nil(t, af.Mkdir(expectedID, 0777))
@@ -366,7 +440,7 @@ func TestHandler_Poll_IntervalsTemperatureUpdate(t *testing.T) {
 	h := ds18b20.New(o)
 	s, _ := h.NewSensor(expectedID)
 
-	errs := s.Poll(readings, interval)
+	errs := s.Poll(context.Background(), readings, interval)
 	require.Nil(t, errs)
 
 	for i := 0; i < 10; i++ {
@@ -378,6 +452,8 @@ func TestHandler_Poll_IntervalsTemperatureUpdate(t *testing.T) {
 			require.EqualValues(t, expectedID, rid)
 			require.EqualValues(t, expectedTemp, tmp)
 			require.Nil(t, err)
+			value, _, _ := r.Value()
+			require.EqualValues(t, 12345, value)
 			diff := stamp.Sub(now)
 			require.Less(t, interval, diff)
 			require.InDelta(t, interval.Milliseconds(), diff.Milliseconds(), float64(interval.Milliseconds())/10)
@@ -399,3 +475,221 @@ func TestHandler_Poll_IntervalsTemperatureUpdate(t *testing.T) {
 	}
 
 }
+
+func TestSensor_PollContext(t *testing.T) {
+	af := afero.Afero{Fs: afero.NewMemMapFs()}
+	defer func() { _ = af.RemoveAll("") }()
+	// Prepare sensor
+	id := "28-05169397aeff"
+	require.Nil(t, af.WriteFile(id+"/temperature", []byte("12345"), 0777))
+
+	o := &iAfero{
+		path: "",
+		a:    afero.NewIOFS(af),
+	}
+	s, err := ds18b20.New(o).NewSensor(id)
+	require.Nil(t, err)
+
+	interval := time.Millisecond
+	require.ErrorIs(t, s.Poll(context.Background(), make(chan ds18b20.Readings), 0), ds18b20.ErrWrongInterval)
+
+	waitClosed := func(readings chan ds18b20.Readings) {
+		for {
+			select {
+			case _, ok := <-readings:
+				if !ok {
+					return
+				}
+			case <-time.After(100 * interval):
+				require.Fail(t, "readings should be closed")
+				return
+			}
+		}
+	}
+
+	t.Run("stop on cancel and restart", func(t *testing.T) {
+		for i := 0; i < 10; i++ {
+			ctx, cancel := context.WithCancel(context.Background())
+			readings := make(chan ds18b20.Readings)
+			require.Nil(t, s.Poll(ctx, readings, interval))
+			// Read few values from time to time
+			if i%2 == 0 {
+				r := <-readings
+				require.Equal(t, id, r.ID())
+			}
+			cancel()
+			waitClosed(readings)
+		}
+	})
+
+	t.Run("stop on close and restart", func(t *testing.T) {
+		for i := 0; i < 10; i++ {
+			readings := make(chan ds18b20.Readings)
+			require.Nil(t, s.Poll(context.Background(), readings, interval))
+			if i%2 == 0 {
+				r := <-readings
+				require.Equal(t, id, r.ID())
+			}
+			require.Nil(t, s.Close())
+			// Channel is already closed, Close can be called again
+			_, ok := <-readings
+			require.False(t, ok)
+			require.Nil(t, s.Close())
+		}
+	})
+
+	t.Run("concurrent poll and close", func(t *testing.T) {
+		const routines = 8
+		readings := make(chan ds18b20.Readings)
+		errs := make(chan error, routines)
+		for i := 0; i < routines; i++ {
+			go func() {
+				errs <- s.Poll(context.Background(), readings, interval)
+			}()
+		}
+		succeeded := 0
+		for i := 0; i < routines; i++ {
+			if err := <-errs; err == nil {
+				succeeded++
+			} else {
+				require.ErrorIs(t, err, ds18b20.ErrAlreadyPolling)
+			}
+		}
+		require.Equal(t, 1, succeeded)
+
+		done := make(chan struct{}, routines)
+		for i := 0; i < routines; i++ {
+			go func() {
+				_ = s.Close()
+				done <- struct{}{}
+			}()
+		}
+		for i := 0; i < routines; i++ {
+			<-done
+		}
+		waitClosed(readings)
+	})
+}
+
+func TestSensor_Resolution(t *testing.T) {
+	af := afero.Afero{Fs: afero.NewMemMapFs()}
+	defer func() { _ = af.RemoveAll("") }()
+	// Prepare sensor
+	id := "28-05169397aeff"
+	require.Nil(t, af.Mkdir(id, 0777))
+	require.Nil(t, af.WriteFile(id+"/temperature", []byte("12345\n"), 0777))
+	require.Nil(t, af.WriteFile(id+"/resolution", []byte("12\n"), 0777))
+
+	o := &iAfero{
+		path: "",
+		a:    afero.NewIOFS(af),
+	}
+	s, err := ds18b20.New(o).NewSensor(id)
+	require.Nil(t, err)
+
+	r, err := s.Resolution()
+	require.Nil(t, err)
+	require.Equal(t, ds18b20.Resolution12Bit, r)
+
+	// Poll faster than conversion is rejected
+	readings := make(chan ds18b20.Readings)
+	err = s.Poll(context.Background(), readings, ds18b20.Resolution12Bit.ConversionTime()-time.Millisecond)
+	require.ErrorIs(t, err, ds18b20.ErrIntervalShort)
+
+	for _, res := range []ds18b20.Resolution{ds18b20.Resolution9Bit, ds18b20.Resolution10Bit, ds18b20.Resolution11Bit, ds18b20.Resolution12Bit} {
+		require.Nil(t, s.SetResolution(res))
+		buf, err := af.ReadFile(id + "/resolution")
+		require.Nil(t, err)
+		require.Equal(t, strconv.Itoa(int(res)), string(buf))
+
+		got, err := s.Resolution()
+		require.Nil(t, err)
+		require.Equal(t, res, got)
+	}
+
+	// 9 bit resolution allows faster polling
+	require.Nil(t, s.SetResolution(ds18b20.Resolution9Bit))
+	require.Nil(t, s.Poll(context.Background(), readings, 100*time.Millisecond))
+	require.Nil(t, s.Close())
+
+	for _, res := range []ds18b20.Resolution{0, 8, 13} {
+		require.ErrorIs(t, s.SetResolution(res), ds18b20.ErrResolution)
+	}
+
+	require.Nil(t, af.WriteFile(id+"/resolution", []byte("7\n"), 0777))
+	_, err = s.Resolution()
+	require.ErrorIs(t, err, ds18b20.ErrResolution)
+}
+
+func TestResolution_ConversionTime(t *testing.T) {
+	require.Equal(t, 93750*time.Microsecond, ds18b20.Resolution9Bit.ConversionTime())
+	require.Equal(t, 187500*time.Microsecond, ds18b20.Resolution10Bit.ConversionTime())
+	require.Equal(t, 375*time.Millisecond, ds18b20.Resolution11Bit.ConversionTime())
+	require.Equal(t, 750*time.Millisecond, ds18b20.Resolution12Bit.ConversionTime())
+}
+
+func TestSensor_W1Slave(t *testing.T) {
+	af := afero.Afero{Fs: afero.NewMemMapFs()}
+	defer func() { _ = af.RemoveAll("") }()
+	// Prepare sensor, which exposes only w1_slave
+	id := "28-05169397aeff"
+	filePath := id + "/w1_slave"
+	require.Nil(t, af.WriteFile(filePath, []byte("72 01 4b 46 7f ff 0e 10 57 : crc=57 YES\n72 01 4b 46 7f ff 0e 10 57 t=23125\n"), 0777))
+
+	o := &iAfero{
+		path: "",
+		a:    afero.NewIOFS(af),
+	}
+	s, err := ds18b20.New(o).NewSensor(id)
+	require.Nil(t, err)
+
+	tests := []struct {
+		name     string
+		write    string
+		expected string
+		err      error
+	}{
+		{
+			name:     "valid",
+			write:    "72 01 4b 46 7f ff 0e 10 57 : crc=57 YES\n72 01 4b 46 7f ff 0e 10 57 t=23125\n",
+			expected: "23.125",
+		},
+		{
+			name:     "negative",
+			write:    "ec ff 4b 46 7f ff 0c 10 7e : crc=7e YES\nec ff 4b 46 7f ff 0c 10 7e t=-1250\n",
+			expected: "-1.250",
+		},
+		{
+			name:  "crc mismatch",
+			write: "72 01 4b 46 7f ff 0e 10 57 : crc=ff NO\n72 01 4b 46 7f ff 0e 10 57 t=23125\n",
+			err:   ds18b20.ErrCRC,
+		},
+		{
+			name:  "missing temperature line",
+			write: "72 01 4b 46 7f ff 0e 10 57 : crc=57 YES\n",
+			err:   ds18b20.ErrInterface,
+		},
+		{
+			name:  "broken crc line",
+			write: "72 01 4b 46 : crc=57 YES\n72 01 4b 46 7f ff 0e 10 57 t=23125\n",
+			err:   ds18b20.ErrInterface,
+		},
+		{
+			name:  "broken temperature line",
+			write: "72 01 4b 46 7f ff 0e 10 57 : crc=57 YES\n72 01 4b 46 7f ff 0e 10 57 23125\n",
+			err:   ds18b20.ErrInterface,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Nil(t, af.WriteFile(filePath, []byte(tt.write), 0777))
+			tmp, err := s.Temperature()
+			if tt.err != nil {
+				require.ErrorIs(t, err, tt.err)
+				return
+			}
+			require.Nil(t, err)
+			require.Equal(t, tt.expected, tmp)
+		})
+	}
+}
@@ -0,0 +1,115 @@
+package ds18b20
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"strconv"
+)
+
+const (
+	// attrEEPROMCmd is used by recent kernels, older ones expose attrEEPROM
+	attrEEPROMCmd = "eeprom_cmd"
+	attrEEPROM    = "eeprom"
+	attrExtPower  = "ext_power"
+	eepromSave    = "save"
+	eepromRestore = "restore"
+)
+
+type PowerMode int
+
+const (
+	PowerUnknown PowerMode = iota
+	PowerExternal
+	PowerParasitic
+)
+
+func (p PowerMode) String() string {
+	switch p {
+	case PowerExternal:
+		return "external"
+	case PowerParasitic:
+		return "parasitic"
+	default:
+		return "unknown"
+	}
+}
+
+// Info gathers sensor settings for diagnostics. Settings not exposed by driver are left with zero values.
+type Info struct {
+	ID         string
+	Family     Family
+	Master     string
+	Resolution Resolution
+	PowerMode  PowerMode
+	AlarmLow   int
+	AlarmHigh  int
+}
+
+// SaveEEPROM stores resolution and alarm thresholds in sensor EEPROM, so they survive power cycle
+func (s *sensor) SaveEEPROM() error {
+	return s.eepromCmd(eepromSave)
+}
+
+// RestoreEEPROM loads resolution and alarm thresholds from sensor EEPROM
+func (s *sensor) RestoreEEPROM() error {
+	if err := s.eepromCmd(eepromRestore); err != nil {
+		return err
+	}
+	// Resolution might have changed
+	if r, err := s.Resolution(); err == nil {
+		s.mtx.Lock()
+		s.resolution = r
+		s.mtx.Unlock()
+	}
+	return nil
+}
+
+func (s *sensor) eepromCmd(cmd string) error {
+	err := s.writeAttr(attrEEPROMCmd, cmd)
+	if errors.Is(err, fs.ErrNotExist) {
+		err = s.writeAttr(attrEEPROM, cmd)
+	}
+	return err
+}
+
+// PowerMode checks, whether sensor is powered externally or parasitically from data line
+func (s *sensor) PowerMode() (PowerMode, error) {
+	conv, err := s.readAttr(attrExtPower)
+	if err != nil {
+		return PowerUnknown, err
+	}
+	value, err := strconv.Atoi(conv)
+	if err != nil {
+		return PowerUnknown, fmt.Errorf("%w: %v", ErrInterface, err)
+	}
+	switch value {
+	case 0:
+		return PowerParasitic, nil
+	case 1:
+		return PowerExternal, nil
+	default:
+		// Negative values are errors reported by driver
+		return PowerUnknown, fmt.Errorf("%w: ext_power %v", ErrInterface, value)
+	}
+}
+
+// Info reads all sensor settings at once
+func (s *sensor) Info() (Info, error) {
+	info := Info{
+		ID:     s.ID(),
+		Family: s.Family(),
+		Master: s.Master(),
+	}
+	var err error
+	if info.Resolution, err = s.Resolution(); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return info, err
+	}
+	if info.PowerMode, err = s.PowerMode(); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return info, err
+	}
+	if info.AlarmLow, info.AlarmHigh, err = s.Alarms(); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return info, err
+	}
+	return info, nil
+}
@@ -0,0 +1,105 @@
+package ds18b20_test
+
+import (
+	"github.com/a-clap/iot/pkg/ds18b20"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+	"os"
+	"testing"
+)
+
+func TestSensor_EEPROM(t *testing.T) {
+	for _, attr := range []string{"eeprom_cmd", "eeprom"} {
+		t.Run(attr, func(t *testing.T) {
+			af := afero.Afero{Fs: afero.NewMemMapFs()}
+			defer func() { _ = af.RemoveAll("") }()
+			id := "28-05169397aeff"
+			require.Nil(t, af.WriteFile(id+"/temperature", []byte("21500\n"), 0777))
+			require.Nil(t, af.WriteFile(id+"/resolution", []byte("12\n"), 0777))
+			require.Nil(t, af.WriteFile(id+"/"+attr, nil, 0777))
+
+			s, err := ds18b20.New(&iAfero{path: "", a: afero.NewIOFS(af)}).NewSensor(id)
+			require.Nil(t, err)
+
+			require.Nil(t, s.SaveEEPROM())
+			buf, err := af.ReadFile(id + "/" + attr)
+			require.Nil(t, err)
+			require.Equal(t, "save", string(buf))
+
+			require.Nil(t, s.RestoreEEPROM())
+			buf, err = af.ReadFile(id + "/" + attr)
+			require.Nil(t, err)
+			require.Equal(t, "restore", string(buf))
+		})
+	}
+
+	t.Run("not supported", func(t *testing.T) {
+		af := afero.Afero{Fs: afero.NewMemMapFs()}
+		defer func() { _ = af.RemoveAll("") }()
+		id := "28-05169397aeff"
+		require.Nil(t, af.WriteFile(id+"/temperature", []byte("21500\n"), 0777))
+
+		s, err := ds18b20.New(&iAfero{path: "", a: afero.NewIOFS(af)}).NewSensor(id)
+		require.Nil(t, err)
+		require.ErrorIs(t, s.SaveEEPROM(), os.ErrNotExist)
+	})
+}
+
+func TestSensor_PowerModeInfo(t *testing.T) {
+	af := afero.Afero{Fs: afero.NewMemMapFs()}
+	defer func() { _ = af.RemoveAll("") }()
+	id := "28-05169397aeff"
+	require.Nil(t, af.WriteFile("bus/"+id+"/temperature", []byte("21500\n"), 0777))
+	require.Nil(t, af.Mkdir("bus/w1_bus_master1/"+id, 0777))
+
+	s, err := ds18b20.New(&iAfero{path: "bus", a: afero.NewIOFS(af)}).NewSensor(id)
+	require.Nil(t, err)
+
+	// Driver doesn't expose anything
+	_, err = s.PowerMode()
+	require.ErrorIs(t, err, os.ErrNotExist)
+	info, err := s.Info()
+	require.Nil(t, err)
+	require.Equal(t, ds18b20.Info{ID: id, Family: ds18b20.DS18B20, Master: "w1_bus_master1"}, info)
+
+	require.Nil(t, af.WriteFile("bus/"+id+"/resolution", []byte("10\n"), 0777))
+	require.Nil(t, af.WriteFile("bus/"+id+"/alarms", []byte("-10 50\n"), 0777))
+
+	tests := []struct {
+		extPower string
+		mode     ds18b20.PowerMode
+		err      error
+	}{
+		{extPower: "0\n", mode: ds18b20.PowerParasitic},
+		{extPower: "1\n", mode: ds18b20.PowerExternal},
+		{extPower: "-5\n", mode: ds18b20.PowerUnknown, err: ds18b20.ErrInterface},
+		{extPower: "yes\n", mode: ds18b20.PowerUnknown, err: ds18b20.ErrInterface},
+	}
+	for _, tt := range tests {
+		require.Nil(t, af.WriteFile("bus/"+id+"/ext_power", []byte(tt.extPower), 0777))
+		mode, err := s.PowerMode()
+		require.Equal(t, tt.mode, mode)
+		if tt.err != nil {
+			require.ErrorIs(t, err, tt.err)
+			_, err = s.Info()
+			require.ErrorIs(t, err, tt.err)
+			continue
+		}
+		require.Nil(t, err)
+
+		info, err := s.Info()
+		require.Nil(t, err)
+		require.Equal(t, ds18b20.Info{
+			ID:         id,
+			Family:     ds18b20.DS18B20,
+			Master:     "w1_bus_master1",
+			Resolution: ds18b20.Resolution10Bit,
+			PowerMode:  tt.mode,
+			AlarmLow:   -10,
+			AlarmHigh:  50,
+		}, info)
+	}
+	require.Equal(t, "parasitic", ds18b20.PowerParasitic.String())
+	require.Equal(t, "external", ds18b20.PowerExternal.String())
+	require.Equal(t, "unknown", ds18b20.PowerUnknown.String())
+}
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"github.com/a-clap/iot/pkg/ds18b20"
 	"github.com/a-clap/logger"
@@ -21,21 +22,15 @@ func main() {
 	}
 	sensor, _ := ds.NewSensor(ids[0])
 
-	errs := sensor.Poll(reads, 750*time.Millisecond)
+	// Just to end this after time
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	errs := sensor.Poll(ctx, reads, 750*time.Millisecond)
 	if errs != nil {
-		log.Fatal(err)
+		log.Fatal(errs)
 	}
 
-	// Just to end this after time
-	go func() {
-		for {
-			select {
-			case <-time.After(10 * time.Second):
-				_ = sensor.Close()
-			}
-		}
-	}()
-
 	for readings := range reads {
 		id := readings.ID()
 		tmp, stamp, err := readings.Get()
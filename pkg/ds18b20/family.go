@@ -0,0 +1,92 @@
+package ds18b20
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Family is a 1-Wire family code - first part of sensor id, e.g. 0x28 for "28-05169397aeff"
+type Family uint8
+
+const (
+	DS18S20 Family = 0x10
+	DS1822  Family = 0x22
+	DS18B20 Family = 0x28
+	// DS1825 shares family code with MAX31850, they can be distinguished only by scratchpad content
+	DS1825   Family = 0x3b
+	MAX31850 Family = DS1825
+	DS28EA00 Family = 0x42
+)
+
+var families = map[Family]string{
+	DS18S20:  "DS18S20",
+	DS1822:   "DS1822",
+	DS18B20:  "DS18B20",
+	DS1825:   "DS1825/MAX31850",
+	DS28EA00: "DS28EA00",
+}
+
+func (f Family) String() string {
+	if name, ok := families[f]; ok {
+		return name
+	}
+	return fmt.Sprintf("unknown(%02x)", uint8(f))
+}
+
+// Supported returns true for families handled by w1_therm driver
+func (f Family) Supported() bool {
+	_, ok := families[f]
+	return ok
+}
+
+// resolutionSettable returns false for families with fixed resolution
+func (f Family) resolutionSettable() bool {
+	return f != DS18S20
+}
+
+// parseFamily returns family code from sensor id
+func parseFamily(id string) (Family, error) {
+	prefix, _, found := strings.Cut(id, "-")
+	if !found || len(prefix) != 2 {
+		return 0, fmt.Errorf("%w: %v", ErrUnsupportedFamily, id)
+	}
+	code, err := strconv.ParseUint(prefix, 16, 8)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %v", ErrUnsupportedFamily, id)
+	}
+	f := Family(code)
+	if !f.Supported() {
+		return f, fmt.Errorf("%w: %v", ErrUnsupportedFamily, id)
+	}
+	return f, nil
+}
+
+// max31850Fault returns error, if MAX31850 reports thermocouple fault in scratchpad.
+// DS1825 has bit 7 of configuration register cleared, MAX31850 has it set.
+func max31850Fault(scratchpad [9]byte) error {
+	const (
+		faultBit  = 1 << 0
+		configReg = 4
+		max31850  = 1 << 7
+		openCirc  = 1 << 0
+		shortGnd  = 1 << 1
+		shortVdd  = 1 << 2
+		faultReg  = 2
+	)
+	if scratchpad[configReg]&max31850 == 0 || scratchpad[0]&faultBit == 0 {
+		return nil
+	}
+	var causes []string
+	fault := scratchpad[faultReg]
+	if fault&openCirc != 0 {
+		causes = append(causes, "open circuit")
+	}
+	if fault&shortGnd != 0 {
+		causes = append(causes, "short to GND")
+	}
+	if fault&shortVdd != 0 {
+		causes = append(causes, "short to VDD")
+	}
+	return fmt.Errorf("%w: %v", ErrThermocoupleFault, strings.Join(causes, ", "))
+}
@@ -0,0 +1,109 @@
+package ds18b20_test
+
+import (
+	"github.com/a-clap/iot/pkg/ds18b20"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestFamily(t *testing.T) {
+	tests := []struct {
+		id     string
+		family ds18b20.Family
+		name   string
+	}{
+		{id: "10-000802b5a3ce", family: ds18b20.DS18S20, name: "DS18S20"},
+		{id: "22-000003c3a8d6", family: ds18b20.DS1822, name: "DS1822"},
+		{id: "28-05169397aeff", family: ds18b20.DS18B20, name: "DS18B20"},
+		{id: "3b-0000001859e2", family: ds18b20.DS1825, name: "DS1825/MAX31850"},
+		{id: "42-00000022c8a1", family: ds18b20.DS28EA00, name: "DS28EA00"},
+	}
+	af := afero.Afero{Fs: afero.NewMemMapFs()}
+	defer func() { _ = af.RemoveAll("") }()
+	for _, tt := range tests {
+		require.Nil(t, af.WriteFile(tt.id+"/temperature", []byte("21500\n"), 0777))
+	}
+	h := ds18b20.New(&iAfero{path: "", a: afero.NewIOFS(af)})
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := h.NewSensor(tt.id)
+			require.Nil(t, err)
+			require.Equal(t, tt.family, s.Family())
+			require.Equal(t, tt.name, s.Family().String())
+			require.True(t, s.Family().Supported())
+		})
+	}
+	require.False(t, ds18b20.Family(0x01).Supported())
+	require.Equal(t, "unknown(01)", ds18b20.Family(0x01).String())
+}
+
+func TestFamily_DS18S20FixedResolution(t *testing.T) {
+	af := afero.Afero{Fs: afero.NewMemMapFs()}
+	defer func() { _ = af.RemoveAll("") }()
+	id := "10-000802b5a3ce"
+	require.Nil(t, af.WriteFile(id+"/temperature", []byte("21500\n"), 0777))
+
+	s, err := ds18b20.New(&iAfero{path: "", a: afero.NewIOFS(af)}).NewSensor(id)
+	require.Nil(t, err)
+	require.ErrorIs(t, s.SetResolution(ds18b20.Resolution9Bit), ds18b20.ErrNotSupported)
+}
+
+func TestFamily_MAX31850Fault(t *testing.T) {
+	af := afero.Afero{Fs: afero.NewMemMapFs()}
+	defer func() { _ = af.RemoveAll("") }()
+	id := "3b-0000001859e2"
+	filePath := id + "/w1_slave"
+
+	tests := []struct {
+		name     string
+		w1Slave  string
+		expected string
+		err      error
+	}{
+		{
+			name:     "MAX31850 without fault",
+			w1Slave:  "60 01 00 17 f0 ff ff ff 10 : crc=10 YES\n60 01 00 17 f0 ff ff ff 10 t=22000\n",
+			expected: "22.000",
+		},
+		{
+			name:    "MAX31850 open circuit",
+			w1Slave: "01 00 01 17 f0 ff ff ff 7a : crc=7a YES\n01 00 01 17 f0 ff ff ff 7a t=0\n",
+			err:     ds18b20.ErrThermocoupleFault,
+		},
+		{
+			name:    "MAX31850 short to VDD",
+			w1Slave: "01 00 04 17 f0 ff ff ff 2e : crc=2e YES\n01 00 04 17 f0 ff ff ff 2e t=0\n",
+			err:     ds18b20.ErrThermocoupleFault,
+		},
+		{
+			name:     "DS1825 uses the same bit as temperature",
+			w1Slave:  "61 01 4b 46 7f ff 0f 10 3c : crc=3c YES\n61 01 4b 46 7f ff 0f 10 3c t=22062\n",
+			expected: "22.062",
+		},
+	}
+	// Kernels since 5.10 expose temperature as well, it doesn't carry fault bits
+	for _, withTemperature := range []bool{false, true} {
+		require.Nil(t, af.RemoveAll(id))
+		if withTemperature {
+			require.Nil(t, af.WriteFile(id+"/temperature", []byte("0\n"), 0777))
+		}
+		require.Nil(t, af.WriteFile(filePath, []byte(tests[0].w1Slave), 0777))
+		s, err := ds18b20.New(&iAfero{path: "", a: afero.NewIOFS(af)}).NewSensor(id)
+		require.Nil(t, err)
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				require.Nil(t, af.WriteFile(filePath, []byte(tt.w1Slave), 0777))
+				tmp, err := s.Temperature()
+				if tt.err != nil {
+					require.ErrorIs(t, err, tt.err)
+					return
+				}
+				require.Nil(t, err)
+				require.Equal(t, tt.expected, tmp)
+			})
+		}
+	}
+}
@@ -0,0 +1,98 @@
+package ds18b20
+
+import (
+	"fmt"
+	"strings"
+)
+
+const masterPrefix = "w1_bus_master"
+
+// Master describes 1-Wire bus master and thermometers connected to it
+type Master struct {
+	Name string
+	IDs  []string
+}
+
+// Masters returns every bus master found along with sensors connected to it
+func (h *Handler) Masters() ([]Master, error) {
+	names, err := h.masterNames()
+	if err != nil {
+		return nil, err
+	}
+	masters := make([]Master, 0, len(names))
+	for _, name := range names {
+		ids, err := h.masterIDs(name)
+		if err != nil {
+			return nil, err
+		}
+		masters = append(masters, Master{Name: name, IDs: ids})
+	}
+	return masters, nil
+}
+
+// masterNames returns names of every w1_bus_masterN on bus
+func (h *Handler) masterNames() ([]string, error) {
+	files, err := h.o.ReadDir(h.o.Path())
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInterface, err)
+	}
+	var names []string
+	for _, f := range files {
+		if strings.HasPrefix(f.Name(), masterPrefix) {
+			names = append(names, f.Name())
+		}
+	}
+	return names, nil
+}
+
+// masterIDs returns ids of sensors, which kernel put under master directory. They are remembered for masterOf.
+func (h *Handler) masterIDs(master string) ([]string, error) {
+	files, err := h.o.ReadDir(h.o.Path() + "/" + master)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInterface, err)
+	}
+	var ids []string
+	for _, f := range files {
+		if _, err := parseFamily(f.Name()); err == nil {
+			ids = append(ids, f.Name())
+		}
+	}
+
+	h.mtx.Lock()
+	defer h.mtx.Unlock()
+	if h.masters == nil {
+		h.masters = make(map[string]string)
+	}
+	for _, id := range ids {
+		h.masters[id] = master
+	}
+	return ids, nil
+}
+
+// masterOf returns name of master, which has sensor with id, or empty string if it is unknown.
+// Masters already listed are remembered, otherwise they are listed until sensor is found.
+func (h *Handler) masterOf(id string) string {
+	h.mtx.Lock()
+	master, ok := h.masters[id]
+	h.mtx.Unlock()
+	if ok {
+		return master
+	}
+
+	names, err := h.masterNames()
+	if err != nil {
+		return ""
+	}
+	for _, name := range names {
+		ids, err := h.masterIDs(name)
+		if err != nil {
+			return ""
+		}
+		for _, masterID := range ids {
+			if masterID == id {
+				return name
+			}
+		}
+	}
+	return ""
+}
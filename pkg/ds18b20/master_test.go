@@ -0,0 +1,112 @@
+package ds18b20_test
+
+import (
+	"github.com/a-clap/iot/pkg/ds18b20"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestHandler_Masters(t *testing.T) {
+	af := afero.Afero{Fs: afero.NewMemMapFs()}
+	defer func() { _ = af.RemoveAll("") }()
+
+	topology := []ds18b20.Master{
+		{Name: "w1_bus_master1", IDs: []string{"28-05169397aeff", "28-0516939fffff"}},
+		{Name: "w1_bus_master2", IDs: []string{"10-000802b5a3ce"}},
+	}
+	for _, m := range topology {
+		require.Nil(t, af.Mkdir("bus/"+m.Name, 0777))
+		for _, id := range m.IDs {
+			// Sensor is available in devices and under its master
+			require.Nil(t, af.WriteFile("bus/"+id+"/temperature", []byte("21500\n"), 0777))
+			require.Nil(t, af.Mkdir("bus/"+m.Name+"/"+id, 0777))
+		}
+	}
+	// Other devices on master are skipped
+	require.Nil(t, af.Mkdir("bus/w1_bus_master2/01-000012345678", 0777))
+
+	h := ds18b20.New(&iAfero{path: "bus", a: afero.NewIOFS(af)})
+	masters, err := h.Masters()
+	require.Nil(t, err)
+	require.Equal(t, topology, masters)
+
+	ids, err := h.IDs()
+	require.Nil(t, err)
+	require.ElementsMatch(t, append(append([]string{}, topology[0].IDs...), topology[1].IDs...), ids)
+
+	for _, m := range topology {
+		for _, id := range m.IDs {
+			s, err := h.NewSensor(id)
+			require.Nil(t, err)
+			require.Equal(t, m.Name, s.Master())
+		}
+	}
+
+	// Sensor not listed under any master
+	require.Nil(t, af.WriteFile("bus/28-000000000001/temperature", []byte("21500\n"), 0777))
+	s, err := h.NewSensor("28-000000000001")
+	require.Nil(t, err)
+	require.Equal(t, "", s.Master())
+}
+
+func TestSensor_MasterLookedUpLazily(t *testing.T) {
+	af := afero.Afero{Fs: afero.NewMemMapFs()}
+	defer func() { _ = af.RemoveAll("") }()
+	ids := []string{"28-05169397aeff", "28-0516939fffff"}
+	for _, id := range ids {
+		require.Nil(t, af.WriteFile("bus/"+id+"/temperature", []byte("21500\n"), 0777))
+		require.Nil(t, af.Mkdir("bus/w1_bus_master1/"+id, 0777))
+	}
+
+	o := &iBulk{iAfero: &iAfero{path: "bus", a: afero.NewIOFS(af)}}
+	h := ds18b20.New(o)
+	var sensors []ds18b20.Sensor
+	for _, id := range ids {
+		s, err := h.NewSensor(id)
+		require.Nil(t, err)
+		sensors = append(sensors, s)
+	}
+	// Listing masters searches bus on some of them, it isn't done just to create sensor
+	require.Zero(t, o.listed())
+
+	for _, s := range sensors {
+		require.Equal(t, "w1_bus_master1", s.Master())
+	}
+	// Master of the second sensor was found, when looking for the first one
+	require.Equal(t, 1, o.listed())
+}
+
+func TestHandler_MastersInterfaceError(t *testing.T) {
+	_, err := ds18b20.New(&iError{}).Masters()
+	require.ErrorIs(t, err, ds18b20.ErrInterface)
+}
+
+func TestNewSysfs(t *testing.T) {
+	root := t.TempDir()
+	id := "28-05169397aeff"
+	require.Nil(t, os.MkdirAll(filepath.Join(root, "w1_bus_master1", id), 0777))
+	require.Nil(t, os.MkdirAll(filepath.Join(root, id), 0777))
+	require.Nil(t, os.WriteFile(filepath.Join(root, id, "temperature"), []byte("-1250\n"), 0666))
+	require.Nil(t, os.WriteFile(filepath.Join(root, id, "resolution"), []byte("12\n"), 0666))
+
+	h := ds18b20.NewSysfs(root)
+	ids, err := h.IDs()
+	require.Nil(t, err)
+	require.Equal(t, []string{id}, ids)
+
+	s, err := h.NewSensor(id)
+	require.Nil(t, err)
+	require.Equal(t, "w1_bus_master1", s.Master())
+
+	tmp, err := s.Temperature()
+	require.Nil(t, err)
+	require.Equal(t, "-1.250", tmp)
+
+	require.Nil(t, s.SetResolution(ds18b20.Resolution9Bit))
+	buf, err := os.ReadFile(filepath.Join(root, id, "resolution"))
+	require.Nil(t, err)
+	require.Equal(t, "9", string(buf))
+}
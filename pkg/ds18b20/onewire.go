@@ -1,15 +1,21 @@
 package ds18b20
 
 import (
+	"io"
 	"io/fs"
 	"os"
+	"strings"
 )
 
+// DefaultPath is where kernel puts devices of every 1-Wire bus master
+const DefaultPath = "/sys/bus/w1/devices"
+
 type onewire struct {
+	root string
 }
 
 func (h *onewire) Path() string {
-	return "/sys/bus/w1/ids"
+	return h.root
 }
 
 func (h *onewire) ReadDir(dirname string) ([]fs.DirEntry, error) {
@@ -19,3 +25,34 @@ func (h *onewire) ReadDir(dirname string) ([]fs.DirEntry, error) {
 func (h *onewire) Open(name string) (File, error) {
 	return os.Open(name)
 }
+
+func (h *onewire) OpenFile(name string, flag int, perm fs.FileMode) (File, error) {
+	return os.OpenFile(name, flag, perm)
+}
+
+// readAttr returns content of sysfs attribute, without trailing newline
+func readAttr(o opener, path string) (string, error) {
+	f, err := o.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	// attributes are just few bytes, io.ReadAll is fine for that purpose
+	buf, err := io.ReadAll(f)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(string(buf), "\r\n"), nil
+}
+
+func writeAttr(o opener, path, value string) error {
+	f, err := o.OpenFile(path, os.O_WRONLY|os.O_TRUNC, 0)
+	if err != nil {
+		return err
+	}
+	_, err = f.Write([]byte(value))
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
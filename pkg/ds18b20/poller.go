@@ -0,0 +1,75 @@
+package ds18b20
+
+import (
+	"context"
+	"time"
+)
+
+// Poll reads temperature of every sensor with id from ids, each pollTime.
+// Readings from single sweep are delivered together, all of them share the same timestamp.
+// If ids is empty, every sensor available on bus at the time of call is polled.
+// Errors of particular sensors (including the ones, which can't be created) are kept in their Readings, polling continues.
+// When bus master supports bulk read, conversion is started on all sensors at once.
+// Returned channel is closed, when ctx is done.
+func (h *Handler) Poll(ctx context.Context, ids []string, pollTime time.Duration) (<-chan []Readings, error) {
+	if pollTime <= 0 {
+		return nil, ErrWrongInterval
+	}
+
+	if len(ids) == 0 {
+		var err error
+		if ids, err = h.readIDs(); err != nil {
+			return nil, err
+		}
+	}
+
+	// Sensors aren't read here, so a single broken one doesn't stop polling of the others
+	targets := make([]target, 0, len(ids))
+	for _, id := range ids {
+		s, err := h.attachSensor(id)
+		if err != nil {
+			targets = append(targets, target{id: id, err: err})
+			continue
+		}
+		if r, err := s.Resolution(); err == nil && pollTime < r.ConversionTime() {
+			return nil, ErrIntervalShort
+		}
+		targets = append(targets, target{id: id, s: s})
+	}
+
+	data := make(chan []Readings)
+	go h.poll(ctx, targets, pollTime, data)
+
+	return data, nil
+}
+
+func (h *Handler) poll(ctx context.Context, targets []target, pollTime time.Duration, data chan []Readings) {
+	defer close(data)
+
+	ticker := time.NewTicker(pollTime)
+	defer ticker.Stop()
+
+	// If master supports bulk read, all sensors convert at once. Masters are listed only once.
+	attrs, _ := h.bulkMasters()
+	bulk := len(attrs) > 0
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		if bulk {
+			// On failure each sensor is just read separately
+			_ = h.bulkConvert(attrs)
+		}
+		batch := readAll(targets)
+
+		select {
+		case <-ctx.Done():
+			return
+		case data <- batch:
+		}
+	}
+}
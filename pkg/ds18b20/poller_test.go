@@ -0,0 +1,152 @@
+package ds18b20_test
+
+import (
+	"context"
+	"github.com/a-clap/iot/pkg/ds18b20"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestHandler_Poll(t *testing.T) {
+	af := afero.Afero{Fs: afero.NewMemMapFs()}
+	defer func() { _ = af.RemoveAll("") }()
+
+	const bus = "bus"
+	sensors := map[string]string{
+		"28-05169397aeff": "12.345",
+		"28-0516939fffff": "23.456",
+	}
+	ids := []string{"28-05169397aeff", "28-0516939fffff"}
+	require.Nil(t, af.WriteFile(bus+"/"+ids[0]+"/temperature", []byte("12345\n"), 0777))
+	require.Nil(t, af.WriteFile(bus+"/"+ids[1]+"/temperature", []byte("23456\n"), 0777))
+
+	h := ds18b20.New(&iAfero{path: bus, a: afero.NewIOFS(af)})
+	interval := 5 * time.Millisecond
+
+	_, err := h.Poll(context.Background(), nil, 0)
+	require.ErrorIs(t, err, ds18b20.ErrWrongInterval)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	data, err := h.Poll(ctx, nil, interval)
+	require.Nil(t, err)
+
+	receive := func() []ds18b20.Readings {
+		select {
+		case batch, ok := <-data:
+			require.True(t, ok)
+			return batch
+		case <-time.After(20 * interval):
+			require.Fail(t, "waiting for readings too long")
+		}
+		return nil
+	}
+
+	var last time.Time
+	for i := 0; i < 3; i++ {
+		batch := receive()
+		require.Len(t, batch, len(ids))
+
+		_, stamp, _ := batch[0].Get()
+		require.True(t, stamp.After(last))
+		last = stamp
+
+		for j, r := range batch {
+			require.Equal(t, ids[j], r.ID())
+			tmp, rstamp, err := r.Get()
+			require.Nil(t, err)
+			require.Equal(t, sensors[r.ID()], tmp)
+			require.Equal(t, stamp, rstamp)
+		}
+	}
+
+	// Error on one sensor doesn't affect the other
+	require.Nil(t, af.Remove(bus+"/"+ids[1]+"/temperature"))
+	// Batch could have been read before removal
+	_ = receive()
+	batch := receive()
+	require.Len(t, batch, len(ids))
+	tmp, _, err := batch[0].Get()
+	require.Nil(t, err)
+	require.Equal(t, sensors[ids[0]], tmp)
+	_, _, err = batch[1].Get()
+	require.ErrorIs(t, err, os.ErrNotExist)
+
+	cancel()
+	for {
+		select {
+		case _, ok := <-data:
+			if !ok {
+				return
+			}
+		case <-time.After(20 * interval):
+			require.Fail(t, "channel should be closed after cancel")
+			return
+		}
+	}
+}
+
+func TestHandler_PollSelectedIDs(t *testing.T) {
+	af := afero.Afero{Fs: afero.NewMemMapFs()}
+	defer func() { _ = af.RemoveAll("") }()
+
+	const bus = "bus"
+	ids := []string{"28-05169397aeff", "28-0516939fffff"}
+	for _, id := range ids {
+		require.Nil(t, af.WriteFile(bus+"/"+id+"/temperature", []byte("1000\n"), 0777))
+	}
+
+	h := ds18b20.New(&iAfero{path: bus, a: afero.NewIOFS(af)})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	data, err := h.Poll(ctx, ids[1:], time.Millisecond)
+	require.Nil(t, err)
+
+	select {
+	case batch := <-data:
+		require.Len(t, batch, 1)
+		require.Equal(t, ids[1], batch[0].ID())
+	case <-time.After(time.Second):
+		require.Fail(t, "waiting for readings too long")
+	}
+}
+
+func TestHandler_PollBrokenSensor(t *testing.T) {
+	af := afero.Afero{Fs: afero.NewMemMapFs()}
+	defer func() { _ = af.RemoveAll("") }()
+
+	const bus = "bus"
+	ids := []string{"28-05169397aeff", "28-0516939fffff", "99-000000000000"}
+	require.Nil(t, af.WriteFile(bus+"/"+ids[0]+"/temperature", []byte("1000\n"), 0777))
+	// Sensor, which reports broken scratchpad
+	require.Nil(t, af.WriteFile(bus+"/"+ids[1]+"/w1_slave", []byte("72 01 4b 46 7f ff 0e 10 57 : crc=00 NO\n72 01 4b 46 7f ff 0e 10 57 t=23125\n"), 0777))
+
+	h := ds18b20.New(&iAfero{path: bus, a: afero.NewIOFS(af)})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	data, err := h.Poll(ctx, append(ids, "28-notexisting"), time.Millisecond)
+	require.Nil(t, err)
+
+	for i := 0; i < 2; i++ {
+		select {
+		case batch := <-data:
+			require.Len(t, batch, 4)
+			tmp, _, err := batch[0].Get()
+			require.Nil(t, err)
+			require.Equal(t, "1.000", tmp)
+			_, _, err = batch[1].Get()
+			require.ErrorIs(t, err, ds18b20.ErrCRC)
+			_, _, err = batch[2].Get()
+			require.ErrorIs(t, err, ds18b20.ErrUnsupportedFamily)
+			require.Equal(t, ids[2], batch[2].ID())
+			_, _, err = batch[3].Get()
+			require.ErrorIs(t, err, os.ErrNotExist)
+		case <-time.After(time.Second):
+			require.Fail(t, "waiting for readings too long")
+		}
+	}
+}
@@ -0,0 +1,70 @@
+package ds18b20
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// Resolution is a number of bits used by sensor for temperature conversion.
+// Higher resolution means better precision, but longer conversion time.
+type Resolution int
+
+const (
+	Resolution9Bit  Resolution = 9
+	Resolution10Bit Resolution = 10
+	Resolution11Bit Resolution = 11
+	Resolution12Bit Resolution = 12
+)
+
+// ConversionTime returns maximum conversion time for resolution, based on datasheet
+func (r Resolution) ConversionTime() time.Duration {
+	switch r {
+	case Resolution9Bit:
+		return 93750 * time.Microsecond
+	case Resolution10Bit:
+		return 187500 * time.Microsecond
+	case Resolution11Bit:
+		return 375 * time.Millisecond
+	default:
+		return 750 * time.Millisecond
+	}
+}
+
+func (r Resolution) valid() bool {
+	return r >= Resolution9Bit && r <= Resolution12Bit
+}
+
+// Resolution reads current resolution from sensor
+func (s *sensor) Resolution() (Resolution, error) {
+	conv, err := s.readAttr(attrResolution)
+	if err != nil {
+		return 0, err
+	}
+	value, err := strconv.Atoi(conv)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %v", ErrInterface, err)
+	}
+	r := Resolution(value)
+	if !r.valid() {
+		return 0, fmt.Errorf("%w: %v", ErrResolution, r)
+	}
+	return r, nil
+}
+
+// SetResolution writes resolution to sensor, it is not persisted in sensor EEPROM
+func (s *sensor) SetResolution(r Resolution) error {
+	if !s.family.resolutionSettable() {
+		return fmt.Errorf("%w: %v", ErrNotSupported, s.family)
+	}
+	if !r.valid() {
+		return fmt.Errorf("%w: %v", ErrResolution, r)
+	}
+	if err := s.writeAttr(attrResolution, strconv.Itoa(int(r))); err != nil {
+		return err
+	}
+	s.mtx.Lock()
+	s.resolution = r
+	s.mtx.Unlock()
+	return nil
+}
@@ -1,147 +1,359 @@
 package ds18b20
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"io"
-	"strings"
+	"io/fs"
+	"sync"
 	"time"
 )
 
+// sysfs attributes exposed by w1_therm driver
+const (
+	attrTemperature = "temperature"
+	attrW1Slave     = "w1_slave"
+	attrResolution  = "resolution"
+)
+
 type Sensor interface {
 	io.Closer
 	ID() string
+	Family() Family
+	Master() string
 	Temperature() (string, error)
-	Poll(readings chan Readings, pollTime time.Duration) (err error)
+	Value() (Millicelsius, error)
+	RawValue() (Millicelsius, error)
+	Calibration() Calibration
+	SetCalibration(c Calibration)
+	Resolution() (Resolution, error)
+	SetResolution(r Resolution) error
+	Alarms() (low, high int, err error)
+	SetAlarms(low, high int) error
+	SaveEEPROM() error
+	RestoreEEPROM() error
+	PowerMode() (PowerMode, error)
+	Info() (Info, error)
+	Poll(ctx context.Context, readings chan Readings, pollTime time.Duration) (err error)
 }
 
 type Readings interface {
 	ID() string
 	Get() (temperature string, timestamp time.Time, err error)
+	Value() (temperature Millicelsius, timestamp time.Time, err error)
+	// Raw returns temperature without calibration applied
+	Raw() Millicelsius
 }
 
 var _ Readings = readings{}
 var _ Sensor = &sensor{}
 
 type readings struct {
-	id, temperature string
-	timestamp       time.Time
-	err             error
+	id          string
+	raw         Millicelsius
+	temperature Millicelsius
+	timestamp   time.Time
+	err         error
 }
 
 type opener interface {
 	Open(name string) (File, error)
+	OpenFile(name string, flag int, perm fs.FileMode) (File, error)
+}
+
+type dirReader interface {
+	ReadDir(dirname string) ([]fs.DirEntry, error)
 }
 
 type sensor struct {
 	opener
-	id      string
-	path    string
-	polling bool
-	fin     chan struct{}
-	stop    chan struct{}
-	data    chan Readings
+	id     string
+	family Family
+	// masterOf looks up master on the first call of Master, it is nil if sensor has no Handler
+	masterOf    func(id string) string
+	masterOnce  sync.Once
+	master      string
+	dir         string
+	attr        string
+	mtx         sync.Mutex
+	resolution  Resolution
+	retries     Retries
+	calibration Calibration
+	cancel      context.CancelFunc
+	done        chan struct{}
 }
 
-func newSensor(o opener, id, basePath string) (*sensor, error) {
-	s := &sensor{
-		opener:  o,
-		id:      id,
-		path:    basePath + "/" + id + "/temperature",
-		polling: false,
+func newSensor(o opener, id, basePath string, args ...any) (*sensor, error) {
+	s, err := attachSensor(o, id, basePath, args...)
+	if err != nil {
+		return nil, err
 	}
-	if _, err := s.Temperature(); err != nil {
+	// Sentinel values don't matter here, sensor is there anyway
+	if _, err := s.read(); err != nil && !isSentinel(err) {
 		return nil, err
 	}
+	// Not every driver exposes resolution, in that case it stays unknown
+	if r, err := s.Resolution(); err == nil {
+		s.resolution = r
+	}
 	return s, nil
 }
 
-func (s *sensor) Poll(data chan Readings, pollTime time.Duration) (err error) {
-	if s.polling {
+// attachSensor creates sensor without communicating with it - neither temperature nor resolution is read,
+// so it is cheap enough to be used for every sensor on bus
+func attachSensor(o opener, id, basePath string, args ...any) (*sensor, error) {
+	family, err := parseFamily(id)
+	if err != nil {
+		return nil, err
+	}
+	s := &sensor{
+		family: family,
+		opener: o,
+		id:     id,
+		dir:    basePath + "/" + id,
+	}
+	s.parse(args...)
+	s.attr = s.chooseAttr()
+	return s, nil
+}
+
+// chooseAttr picks attribute to read temperature from, by listing sensor directory.
+// Newer kernels expose temperature, older ones only w1_slave.
+// MAX31850 reports thermocouple faults only in scratchpad, so w1_slave is preferred for it.
+// If directory can't be listed, preferred attribute is tried first and read falls back to the other one.
+func (s *sensor) chooseAttr() string {
+	preferred := attrTemperature
+	if s.family == MAX31850 {
+		preferred = attrW1Slave
+	}
+	lister, ok := s.opener.(dirReader)
+	if !ok {
+		return preferred
+	}
+	entries, err := lister.ReadDir(s.dir)
+	if err != nil {
+		return preferred
+	}
+	attrs := make(map[string]bool, len(entries))
+	for _, e := range entries {
+		attrs[e.Name()] = true
+	}
+	if attrs[attrW1Slave] && (s.family == MAX31850 || !attrs[attrTemperature]) {
+		return attrW1Slave
+	}
+	return attrTemperature
+}
+
+// Poll reads temperature every pollTime and sends it to data.
+// Polling stops, when ctx is done or Close is called, then data is closed.
+// Sensor can be polled again, after previous polling finished.
+func (s *sensor) Poll(ctx context.Context, data chan Readings, pollTime time.Duration) (err error) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	if s.cancel != nil {
 		return ErrAlreadyPolling
 	}
+	if pollTime <= 0 {
+		return ErrWrongInterval
+	}
+	if s.resolution != 0 && pollTime < s.resolution.ConversionTime() {
+		return ErrIntervalShort
+	}
 
-	s.polling = true
-	s.fin = make(chan struct{})
-	s.stop = make(chan struct{})
-	s.data = data
-	go s.poll(pollTime)
+	ctx, s.cancel = context.WithCancel(ctx)
+	s.done = make(chan struct{})
+	go s.poll(ctx, data, pollTime)
 
 	return nil
 }
 
+// Close stops polling and waits until data channel is closed. It is safe to call it, even if sensor is not polling.
 func (s *sensor) Close() error {
-	if s.polling {
-		return nil
-	}
-	s.stop <- struct{}{}
-	// Close stop channel, not needed anymore
-	close(s.stop)
-	// Unblock poll
-	for range s.data {
-	}
-	// Wait until finish
-	for range s.fin {
+	s.mtx.Lock()
+	cancel, done := s.cancel, s.done
+	s.mtx.Unlock()
+
+	if cancel != nil {
+		cancel()
+		<-done
 	}
 
 	return nil
 }
 
-func (s *sensor) poll(pollTime time.Duration) {
+func (s *sensor) poll(ctx context.Context, data chan Readings, pollTime time.Duration) {
+	defer func() {
+		// sensor is the sender side, so it is responsible for closing data
+		close(data)
+
+		s.mtx.Lock()
+		s.cancel()
+		s.cancel = nil
+		close(s.done)
+		s.mtx.Unlock()
+	}()
 
-	for s.polling {
+	for {
 		select {
-		case <-s.stop:
-			s.polling = false
+		case <-ctx.Done():
+			return
 		case <-time.After(pollTime):
-			tmp, err := s.Temperature()
-			r := readings{
-				id:          s.ID(),
-				temperature: tmp,
-				timestamp:   time.Now(),
-				err:         err,
-			}
-			s.data <- r
+		}
+
+		raw, tmp, err := s.measure()
+		r := readings{
+			id:          s.ID(),
+			raw:         raw,
+			temperature: tmp,
+			timestamp:   time.Now(),
+			err:         err,
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case data <- r:
 		}
 	}
-	close(s.data)
-	// For sure there won't be more data
-	// sensor created channel (and is the sender side), so should close
-	s.fin <- struct{}{}
-	close(s.fin)
 }
 
+// Temperature returns temperature formatted as string, e.g. "-1.250"
 func (s *sensor) Temperature() (string, error) {
-	f, err := s.Open(s.path)
+	tmp, err := s.Value()
 	if err != nil {
 		return "", err
 	}
-	// sensor temperature file is just few bytes, io.ReadAll is fine for that purpose
-	buf, err := io.ReadAll(f)
+	return tmp.String(), nil
+}
+
+// Value returns temperature in millidegrees Celsius.
+// Sentinel values are reported as ErrPowerOnReset or ErrDisconnected, sensor is read again up to Retries times.
+func (s *sensor) Value() (Millicelsius, error) {
+	_, tmp, err := s.measure()
+	return tmp, err
+}
+
+// RawValue returns temperature in millidegrees Celsius, without calibration applied
+func (s *sensor) RawValue() (Millicelsius, error) {
+	raw, _, err := s.measure()
+	return raw, err
+}
+
+// measure reads sensor and returns both raw and calibrated temperature
+func (s *sensor) measure() (raw, tmp Millicelsius, err error) {
+	raw, err = s.read()
+	for i := Retries(0); i < s.retries && isSentinel(err); i++ {
+		raw, err = s.read()
+	}
 	if err != nil {
-		return "", err
+		return raw, raw, err
 	}
-	conv := strings.TrimRight(string(buf), "\r\n")
-	length := len(conv)
-	if length > 3 {
-		conv = conv[:length-3] + "." + conv[length-3:]
-	} else {
-		leading := "0."
-		for length < 3 {
-			leading += "0"
-			length++
+	return raw, s.Calibration().apply(raw), nil
+}
+
+func (s *sensor) read() (Millicelsius, error) {
+	s.mtx.Lock()
+	attr := s.attr
+	s.mtx.Unlock()
+
+	conv, err := s.readAttr(attr)
+	if errors.Is(err, fs.ErrNotExist) {
+		// Attribute guessed by chooseAttr doesn't exist, the other one has to
+		if attr == attrTemperature {
+			attr = attrW1Slave
+		} else {
+			attr = attrTemperature
+		}
+		if conv, err = s.readAttr(attr); err == nil {
+			s.mtx.Lock()
+			s.attr = attr
+			s.mtx.Unlock()
+		}
+	}
+	if err != nil {
+		return 0, err
+	}
+	var tmp Millicelsius
+	if attr == attrW1Slave {
+		var w w1Slave
+		if w, err = parseW1Slave(conv); err != nil {
+			return 0, err
+		}
+		if w.empty() {
+			return 0, fmt.Errorf("%w: empty scratchpad", ErrDisconnected)
+		}
+		if s.family == MAX31850 {
+			if err := max31850Fault(w.scratchpad); err != nil {
+				return 0, err
+			}
+		}
+		tmp = w.temperature
+	} else if tmp, err = parseMillicelsius(conv); err != nil {
+		return 0, err
+	}
+	return tmp, checkSentinel(tmp)
+}
+
+func (s *sensor) parse(args ...any) {
+	for _, arg := range args {
+		switch arg := arg.(type) {
+		case Retries:
+			s.retries = arg
+		case Calibration:
+			s.calibration = arg
+		case Calibrations:
+			if c, ok := arg[s.id]; ok {
+				s.calibration = c
+			}
 		}
-		conv = leading + conv
 	}
-	return conv, nil
 }
 
 func (s *sensor) ID() string {
 	return s.id
 }
 
+func (s *sensor) Family() Family {
+	return s.family
+}
+
+// Master returns name of bus master, which sensor is connected to, e.g. "w1_bus_master1".
+// It is empty, if master is unknown.
+func (s *sensor) Master() string {
+	s.masterOnce.Do(func() {
+		if s.masterOf != nil {
+			s.master = s.masterOf(s.id)
+		}
+	})
+	return s.master
+}
+
+// readAttr returns content of sensor sysfs attribute, without trailing newline
+func (s *sensor) readAttr(name string) (string, error) {
+	return readAttr(s.opener, s.dir+"/"+name)
+}
+
+func (s *sensor) writeAttr(name, value string) error {
+	return writeAttr(s.opener, s.dir+"/"+name, value)
+}
+
 func (r readings) ID() string {
 	return r.id
 }
 
 func (r readings) Get() (temperature string, timestamp time.Time, err error) {
+	if r.err == nil {
+		temperature = r.temperature.String()
+	}
+	return temperature, r.timestamp, r.err
+}
+
+func (r readings) Value() (temperature Millicelsius, timestamp time.Time, err error) {
 	return r.temperature, r.timestamp, r.err
 }
+
+func (r readings) Raw() Millicelsius {
+	return r.raw
+}
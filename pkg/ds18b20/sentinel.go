@@ -0,0 +1,32 @@
+package ds18b20
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Retries is a number of additional reads, when sensor reports sentinel value.
+// Single retry is usually enough to get over power-on reset value.
+type Retries uint
+
+const (
+	// powerOnReset is a value of temperature register after power-on (or brown-out)
+	powerOnReset Millicelsius = 85000
+	// disconnected is reported by w1_therm, when sensor didn't respond
+	disconnected Millicelsius = -127000
+)
+
+// checkSentinel classifies temperature value, which is not an actual measurement
+func checkSentinel(tmp Millicelsius) error {
+	switch tmp {
+	case powerOnReset:
+		return fmt.Errorf("%w: %v", ErrPowerOnReset, tmp)
+	case disconnected:
+		return fmt.Errorf("%w: %v", ErrDisconnected, tmp)
+	}
+	return nil
+}
+
+func isSentinel(err error) bool {
+	return errors.Is(err, ErrPowerOnReset) || errors.Is(err, ErrDisconnected)
+}
@@ -0,0 +1,168 @@
+package ds18b20_test
+
+import (
+	"context"
+	"errors"
+	"github.com/a-clap/iot/pkg/ds18b20"
+	"github.com/stretchr/testify/require"
+	"io/fs"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// iSequence serves consecutive values on each read of temperature
+type iSequence struct {
+	mtx    sync.Mutex
+	values []string
+	reads  int
+}
+
+type seqFile struct {
+	*strings.Reader
+}
+
+func (s seqFile) Write([]byte) (int, error) {
+	return 0, errors.New("read only")
+}
+
+func (s seqFile) Close() error {
+	return nil
+}
+
+func (i *iSequence) Path() string {
+	return ""
+}
+
+func (i *iSequence) ReadDir(string) ([]fs.DirEntry, error) {
+	return nil, nil
+}
+
+func (i *iSequence) Open(name string) (ds18b20.File, error) {
+	if !strings.HasSuffix(name, "/temperature") {
+		return nil, os.ErrNotExist
+	}
+	i.mtx.Lock()
+	defer i.mtx.Unlock()
+	value := i.values[len(i.values)-1]
+	if i.reads < len(i.values) {
+		value = i.values[i.reads]
+	}
+	i.reads++
+	return seqFile{strings.NewReader(value)}, nil
+}
+
+func (i *iSequence) OpenFile(name string, _ int, _ fs.FileMode) (ds18b20.File, error) {
+	return i.Open(name)
+}
+
+func TestSensor_Sentinel(t *testing.T) {
+	const id = "28-05169397aeff"
+	tests := []struct {
+		name     string
+		args     []any
+		values   []string
+		expected string
+		err      error
+	}{
+		{
+			name:     "valid value",
+			values:   []string{"0", "21500"},
+			expected: "21.500",
+		},
+		{
+			name:   "power on reset",
+			values: []string{"0", "85000"},
+			err:    ds18b20.ErrPowerOnReset,
+		},
+		{
+			name:   "disconnected",
+			values: []string{"0", "-127000"},
+			err:    ds18b20.ErrDisconnected,
+		},
+		{
+			name:     "retry once after power on reset",
+			args:     []any{ds18b20.Retries(1)},
+			values:   []string{"0", "85000", "21500"},
+			expected: "21.500",
+		},
+		{
+			name:   "retry once isn't enough",
+			args:   []any{ds18b20.Retries(1)},
+			values: []string{"0", "85000", "-127000", "21500"},
+			err:    ds18b20.ErrDisconnected,
+		},
+		{
+			name:     "retry twice",
+			args:     []any{ds18b20.Retries(2)},
+			values:   []string{"0", "85000", "-127000", "21500"},
+			expected: "21.500",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := ds18b20.New(&iSequence{values: tt.values}).NewSensor(id, tt.args...)
+			require.Nil(t, err)
+
+			tmp, err := s.Temperature()
+			if tt.err != nil {
+				require.ErrorIs(t, err, tt.err)
+				return
+			}
+			require.Nil(t, err)
+			require.Equal(t, tt.expected, tmp)
+		})
+	}
+}
+
+func TestSensor_SentinelOnCreation(t *testing.T) {
+	// Sensor which reports power on reset is still there
+	s, err := ds18b20.New(&iSequence{values: []string{"85000"}}).NewSensor("28-05169397aeff")
+	require.Nil(t, err)
+	_, err = s.Value()
+	require.ErrorIs(t, err, ds18b20.ErrPowerOnReset)
+}
+
+func TestSensor_SentinelPoll(t *testing.T) {
+	o := &iSequence{values: []string{"0", "85000", "21500", "85000", "-127000"}}
+	s, err := ds18b20.New(o, ds18b20.Retries(1)).NewSensor("28-05169397aeff")
+	require.Nil(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	readings := make(chan ds18b20.Readings)
+	require.Nil(t, s.Poll(ctx, readings, time.Millisecond))
+
+	r := <-readings
+	tmp, _, err := r.Value()
+	require.Nil(t, err)
+	require.EqualValues(t, 21500, tmp)
+
+	r = <-readings
+	_, _, err = r.Value()
+	require.ErrorIs(t, err, ds18b20.ErrDisconnected)
+}
+
+func TestSensor_W1SlaveDisconnected(t *testing.T) {
+	o := &iSequence{values: []string{
+		"00 00 00 00 00 00 00 00 00 : crc=00 YES\n00 00 00 00 00 00 00 00 00 t=0\n",
+	}}
+	// iSequence serves only temperature, so wrap it
+	s, err := ds18b20.New(&w1SlaveOnly{o}).NewSensor("28-05169397aeff")
+	require.Nil(t, err)
+	_, err = s.Value()
+	require.ErrorIs(t, err, ds18b20.ErrDisconnected)
+}
+
+type w1SlaveOnly struct {
+	*iSequence
+}
+
+func (w *w1SlaveOnly) Open(name string) (ds18b20.File, error) {
+	if !strings.HasSuffix(name, "/w1_slave") {
+		return nil, os.ErrNotExist
+	}
+	return w.iSequence.Open(strings.TrimSuffix(name, "/w1_slave") + "/temperature")
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"github.com/a-clap/iot/pkg/ds18b20"
+	"github.com/a-clap/iot/pkg/ds18b20/simulator"
+	"github.com/a-clap/logger"
+	"go.uber.org/zap/zapcore"
+	"time"
+)
+
+func main() {
+	log := logger.NewDefaultZap(zapcore.DebugLevel)
+
+	bus := simulator.New()
+	// Heating up, with a bit of noise
+	heating := simulator.Noise(simulator.Ramp(20000, 80000, 10*time.Second), 250, time.Now().UnixNano())
+	// Sensor on a long cable, which drops out from time to time
+	flaky := simulator.Dropouts(simulator.Constant(21500), 3*time.Second, time.Second)
+	if err := bus.Add("28-05169397aeff", heating); err != nil {
+		log.Fatal(err)
+	}
+	if err := bus.Add("28-0516939725ff", flaky); err != nil {
+		log.Fatal(err)
+	}
+
+	ds := ds18b20.New(bus)
+
+	// Just to end this after time
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	batches, err := ds.Poll(ctx, nil, time.Second)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	for batch := range batches {
+		for _, readings := range batch {
+			tmp, stamp, err := readings.Get()
+			fmt.Printf("id: %s, Temperature: %s. Time: %s, err: %v \n", readings.ID(), tmp, stamp, err)
+		}
+	}
+
+	fmt.Println("finished")
+}
@@ -0,0 +1,129 @@
+package simulator
+
+import (
+	"github.com/a-clap/iot/pkg/ds18b20"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// Fault is a failure of virtual sensor, which happens instead of a proper measurement
+type Fault int
+
+const (
+	// NoFault means sensor measures correctly
+	NoFault Fault = iota
+	// Dropout means sensor doesn't respond, w1_therm reports it as -127°C with empty scratchpad
+	Dropout
+	// CRCFailure means scratchpad was corrupted on bus
+	CRCFailure
+)
+
+func (f Fault) String() string {
+	switch f {
+	case NoFault:
+		return "none"
+	case Dropout:
+		return "dropout"
+	case CRCFailure:
+		return "crc failure"
+	}
+	return "unknown"
+}
+
+// Sample is a state of virtual sensor at some point in time
+type Sample struct {
+	Temperature ds18b20.Millicelsius
+	Fault       Fault
+}
+
+// Script describes, how virtual sensor behaves in time.
+// elapsed is a time since Bus was created (or since start of Step in Sequence).
+type Script interface {
+	At(elapsed time.Duration) Sample
+}
+
+// ScriptFunc adapts ordinary function to Script
+type ScriptFunc func(elapsed time.Duration) Sample
+
+func (f ScriptFunc) At(elapsed time.Duration) Sample {
+	return f(elapsed)
+}
+
+// Constant keeps temperature all the time
+func Constant(temperature ds18b20.Millicelsius) Script {
+	return ScriptFunc(func(time.Duration) Sample {
+		return Sample{Temperature: temperature}
+	})
+}
+
+// Ramp changes temperature linearly from one value to another over duration, then stays at to
+func Ramp(from, to ds18b20.Millicelsius, duration time.Duration) Script {
+	return ScriptFunc(func(elapsed time.Duration) Sample {
+		if elapsed >= duration || duration <= 0 {
+			return Sample{Temperature: to}
+		}
+		delta := int64(to-from) * int64(elapsed) / int64(duration)
+		return Sample{Temperature: from + ds18b20.Millicelsius(delta)}
+	})
+}
+
+// Noise adds uniformly distributed noise in range [-amplitude, amplitude] to script.
+// Same seed gives same sequence of readings.
+func Noise(script Script, amplitude ds18b20.Millicelsius, seed int64) Script {
+	var (
+		mtx sync.Mutex
+		rnd = rand.New(rand.NewSource(seed))
+	)
+	return ScriptFunc(func(elapsed time.Duration) Sample {
+		s := script.At(elapsed)
+		if amplitude <= 0 {
+			return s
+		}
+		mtx.Lock()
+		noise := ds18b20.Millicelsius(rnd.Int63n(2*int64(amplitude)+1)) - amplitude
+		mtx.Unlock()
+		s.Temperature += noise
+		return s
+	})
+}
+
+// Dropouts makes sensor stop responding for duration at the end of each period
+func Dropouts(script Script, period, duration time.Duration) Script {
+	return faults(script, Dropout, period, duration)
+}
+
+// CRCFailures corrupts scratchpad for duration at the end of each period
+func CRCFailures(script Script, period, duration time.Duration) Script {
+	return faults(script, CRCFailure, period, duration)
+}
+
+func faults(script Script, fault Fault, period, duration time.Duration) Script {
+	return ScriptFunc(func(elapsed time.Duration) Sample {
+		s := script.At(elapsed)
+		if period > 0 && elapsed%period >= period-duration {
+			s.Fault = fault
+		}
+		return s
+	})
+}
+
+// Step is a part of Sequence, Script runs for Duration
+type Step struct {
+	Duration time.Duration
+	Script   Script
+}
+
+// Sequence runs steps one after another, the last one lasts forever.
+// Each step sees elapsed time since its own start.
+func Sequence(steps ...Step) Script {
+	return ScriptFunc(func(elapsed time.Duration) Sample {
+		for i, step := range steps {
+			if elapsed < step.Duration || i == len(steps)-1 {
+				return step.Script.At(elapsed)
+			}
+			elapsed -= step.Duration
+		}
+		return Sample{Fault: Dropout}
+	})
+}
@@ -0,0 +1,100 @@
+package simulator_test
+
+import (
+	"github.com/a-clap/iot/pkg/ds18b20"
+	"github.com/a-clap/iot/pkg/ds18b20/simulator"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestScripts(t *testing.T) {
+	tests := []struct {
+		name     string
+		script   simulator.Script
+		elapsed  time.Duration
+		expected simulator.Sample
+	}{
+		{
+			name:     "constant",
+			script:   simulator.Constant(21500),
+			elapsed:  time.Hour,
+			expected: simulator.Sample{Temperature: 21500},
+		},
+		{
+			name:     "ramp in the middle",
+			script:   simulator.Ramp(20000, 30000, 10*time.Second),
+			elapsed:  5 * time.Second,
+			expected: simulator.Sample{Temperature: 25000},
+		},
+		{
+			name:     "ramp down",
+			script:   simulator.Ramp(30000, -10000, 4*time.Second),
+			elapsed:  time.Second,
+			expected: simulator.Sample{Temperature: 20000},
+		},
+		{
+			name:     "ramp finished",
+			script:   simulator.Ramp(20000, 30000, 10*time.Second),
+			elapsed:  time.Minute,
+			expected: simulator.Sample{Temperature: 30000},
+		},
+		{
+			name:     "dropout at the end of period",
+			script:   simulator.Dropouts(simulator.Constant(1000), 10*time.Second, 2*time.Second),
+			elapsed:  19 * time.Second,
+			expected: simulator.Sample{Temperature: 1000, Fault: simulator.Dropout},
+		},
+		{
+			name:     "no dropout at the beginning of period",
+			script:   simulator.Dropouts(simulator.Constant(1000), 10*time.Second, 2*time.Second),
+			elapsed:  11 * time.Second,
+			expected: simulator.Sample{Temperature: 1000},
+		},
+		{
+			name:     "crc failure",
+			script:   simulator.CRCFailures(simulator.Constant(1000), time.Second, time.Second),
+			elapsed:  0,
+			expected: simulator.Sample{Temperature: 1000, Fault: simulator.CRCFailure},
+		},
+		{
+			name: "sequence second step",
+			script: simulator.Sequence(
+				simulator.Step{Duration: 10 * time.Second, Script: simulator.Ramp(0, 10000, 10*time.Second)},
+				simulator.Step{Duration: 10 * time.Second, Script: simulator.Ramp(10000, 0, 10*time.Second)},
+			),
+			elapsed:  12 * time.Second,
+			expected: simulator.Sample{Temperature: 8000},
+		},
+		{
+			name: "sequence last step lasts forever",
+			script: simulator.Sequence(
+				simulator.Step{Duration: time.Second, Script: simulator.Constant(1)},
+				simulator.Step{Duration: time.Second, Script: simulator.Constant(2)},
+			),
+			elapsed:  time.Hour,
+			expected: simulator.Sample{Temperature: 2},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.expected, tt.script.At(tt.elapsed))
+		})
+	}
+}
+
+func TestNoise(t *testing.T) {
+	r := require.New(t)
+	const amplitude ds18b20.Millicelsius = 500
+
+	first := simulator.Noise(simulator.Constant(20000), amplitude, 1)
+	second := simulator.Noise(simulator.Constant(20000), amplitude, 1)
+	varies := false
+	for i := 0; i < 100; i++ {
+		s := first.At(0)
+		r.InDelta(20000, int32(s.Temperature), float64(amplitude))
+		r.Equal(s, second.At(0), "same seed, same noise")
+		varies = varies || s.Temperature != 20000
+	}
+	r.True(varies)
+}
@@ -0,0 +1,128 @@
+package simulator
+
+import (
+	"fmt"
+	"github.com/a-clap/iot/internal/w1"
+	"github.com/a-clap/iot/pkg/ds18b20"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// disconnected is reported by w1_therm, when sensor didn't respond
+	disconnected = -127000
+)
+
+// temperature returns content of temperature attribute
+func (s *sensor) temperature(elapsed time.Duration) (string, error) {
+	sample := s.script.At(elapsed)
+	switch sample.Fault {
+	case Dropout:
+		return strconv.Itoa(disconnected), nil
+	case CRCFailure:
+		return "", fmt.Errorf("%w: scratchpad corrupted", ds18b20.ErrCRC)
+	}
+	_, tmp := s.convert(sample.Temperature)
+	return strconv.FormatInt(tmp, 10), nil
+}
+
+// w1Slave returns content of w1_slave attribute, with scratchpad and its crc
+func (s *sensor) w1Slave(elapsed time.Duration) string {
+	var (
+		scratchpad [9]byte
+		tmp        int64
+		valid      = "YES"
+	)
+	sample := s.script.At(elapsed)
+	if sample.Fault != Dropout {
+		scratchpad, tmp = s.convert(sample.Temperature)
+	}
+	// Sensor which doesn't respond leaves scratchpad zeroed, and crc of zeros is zero
+	crc := w1.CRC8(scratchpad[:8])
+	if sample.Fault == CRCFailure {
+		scratchpad[0] ^= 0x01
+		valid = "NO"
+	}
+	scratchpad[8] = crc
+
+	bytes := make([]string, len(scratchpad))
+	for i, b := range scratchpad {
+		bytes[i] = fmt.Sprintf("%02x", b)
+	}
+	hex := strings.Join(bytes, " ")
+	return fmt.Sprintf("%s : crc=%02x %s\n%s t=%d", hex, crc, valid, hex, tmp)
+}
+
+// convert quantizes temperature the way sensor does, returns scratchpad and temperature reported by w1_therm
+func (s *sensor) convert(temperature ds18b20.Millicelsius) (scratchpad [9]byte, tmp int64) {
+	scratchpad[2], scratchpad[3] = byte(int8(s.ram.high)), byte(int8(s.ram.low))
+	scratchpad[5], scratchpad[7] = 0xFF, 0x10
+
+	if s.family == ds18b20.DS18S20 {
+		// 0.5°C register, extended with COUNT_REMAIN to 1/16°C
+		sixteenths := floorDiv(int64(temperature)*16, 1000)
+		whole := floorDiv(sixteenths, 16)
+		countRemain := 16 - (sixteenths - whole*16) - 4
+		if countRemain <= 0 {
+			whole++
+			countRemain += 16
+		}
+		raw := uint16(int16(whole * 2))
+		scratchpad[0], scratchpad[1] = byte(raw), byte(raw>>8)
+		scratchpad[4], scratchpad[6] = 0xFF, byte(countRemain)
+		tmp = whole*1000 - 250 + 1000*(16-int64(countRemain))/16
+	} else {
+		sixteenths := floorDiv(int64(temperature)*16, 1000)
+		// Lower bits are undefined in lower resolutions, sensor keeps them zeroed
+		sixteenths &^= int64(1)<<(12-s.ram.resolution) - 1
+		raw := uint16(int16(sixteenths))
+		scratchpad[0], scratchpad[1] = byte(raw), byte(raw>>8)
+		scratchpad[4], scratchpad[6] = byte(s.ram.resolution-9)<<5|0x1F, 0x0C
+		tmp = sixteenths * 1000 / 16
+	}
+	scratchpad[8] = w1.CRC8(scratchpad[:8])
+	return scratchpad, tmp
+}
+
+func (s *sensor) hasResolution() bool {
+	return s.family != ds18b20.DS18S20
+}
+
+func (s *sensor) setResolution(value string) error {
+	bits, err := strconv.Atoi(value)
+	if err != nil || bits < 9 || bits > 12 {
+		return fmt.Errorf("%w: resolution %q", ErrAttr, value)
+	}
+	s.ram.resolution = bits
+	return nil
+}
+
+func (s *sensor) setAlarms(value string) error {
+	low, high, ok := w1.ParseAlarms(value)
+	if !ok {
+		return fmt.Errorf("%w: alarms %q", ErrAttr, value)
+	}
+	s.ram.low, s.ram.high = low, high
+	return nil
+}
+
+func (s *sensor) eepromCmd(value string) error {
+	switch value {
+	case "save":
+		s.eeprom = s.ram
+	case "restore":
+		s.ram = s.eeprom
+	default:
+		return fmt.Errorf("%w: eeprom_cmd %q", ErrAttr, value)
+	}
+	return nil
+}
+
+func floorDiv(a, b int64) int64 {
+	q := a / b
+	if (a%b != 0) && ((a < 0) != (b < 0)) {
+		q--
+	}
+	return q
+}
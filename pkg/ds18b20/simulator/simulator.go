@@ -0,0 +1,296 @@
+// Package simulator provides virtual 1-Wire bus with scripted thermometers.
+// Bus implements ds18b20.Onewire and mimics w1_therm sysfs attributes,
+// so ds18b20.Handler can be used on machine without any 1-Wire master.
+package simulator
+
+import (
+	"errors"
+	"fmt"
+	"github.com/a-clap/iot/internal/w1"
+	"github.com/a-clap/iot/pkg/ds18b20"
+	"io/fs"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultRoot is a virtual path of Bus, when Root is not passed
+const DefaultRoot = "/simulator"
+
+const (
+	attrTemperature = "temperature"
+	attrW1Slave     = "w1_slave"
+	attrResolution  = "resolution"
+	attrAlarms      = "alarms"
+	attrExtPower    = "ext_power"
+	attrEEPROMCmd   = "eeprom_cmd"
+	attrBulkRead    = "therm_bulk_read"
+	masterName      = "w1_bus_master1"
+)
+
+var (
+	ErrID     = errors.New("malformed sensor id")
+	ErrExists = errors.New("sensor already exists")
+	ErrAttr   = errors.New("invalid attribute value")
+)
+
+// Root is a virtual path, under which Bus exposes its sensors
+type Root string
+
+// Clock returns current time, time.Now is used by default
+type Clock func() time.Time
+
+// Bus is a single virtual 1-Wire master with thermometers attached
+type Bus struct {
+	mtx     sync.Mutex
+	root    string
+	clock   Clock
+	start   time.Time
+	sensors map[string]*sensor
+}
+
+// sensor holds state of virtual thermometer
+type sensor struct {
+	family ds18b20.Family
+	script Script
+	// settings in RAM and their copy in EEPROM
+	ram, eeprom settings
+}
+
+type settings struct {
+	resolution int
+	low, high  int
+}
+
+var _ ds18b20.Onewire = &Bus{}
+
+// New creates empty Bus. Time seen by scripts starts now.
+func New(args ...any) *Bus {
+	b := &Bus{
+		root:    DefaultRoot,
+		clock:   time.Now,
+		sensors: make(map[string]*sensor),
+	}
+	b.parse(args...)
+	b.start = b.clock()
+	return b
+}
+
+func (b *Bus) parse(args ...any) {
+	for _, arg := range args {
+		switch arg := arg.(type) {
+		case Root:
+			b.root = string(arg)
+		case Clock:
+			b.clock = arg
+		}
+	}
+}
+
+// Add attaches thermometer with id (e.g. "28-05169397aeff") driven by script
+func (b *Bus) Add(id string, script Script) error {
+	family, err := parseID(id)
+	if err != nil {
+		return err
+	}
+
+	b.mtx.Lock()
+	defer b.mtx.Unlock()
+	if _, ok := b.sensors[id]; ok {
+		return fmt.Errorf("%w: %v", ErrExists, id)
+	}
+	// Factory defaults of DS18B20
+	defaults := settings{resolution: 12, low: 70, high: 75}
+	b.sensors[id] = &sensor{family: family, script: script, ram: defaults, eeprom: defaults}
+	return nil
+}
+
+// Remove detaches thermometer from bus
+func (b *Bus) Remove(id string) error {
+	b.mtx.Lock()
+	defer b.mtx.Unlock()
+	if _, ok := b.sensors[id]; !ok {
+		return w1.ErrNotExist(id)
+	}
+	delete(b.sensors, id)
+	return nil
+}
+
+// SetScript replaces script of thermometer, elapsed time is still counted since Bus creation
+func (b *Bus) SetScript(id string, script Script) error {
+	b.mtx.Lock()
+	defer b.mtx.Unlock()
+	s, ok := b.sensors[id]
+	if !ok {
+		return w1.ErrNotExist(id)
+	}
+	s.script = script
+	return nil
+}
+
+func parseID(id string) (ds18b20.Family, error) {
+	family, serial, found := strings.Cut(id, "-")
+	if !found || len(family) != 2 || len(serial) != 12 {
+		return 0, fmt.Errorf("%w: %v", ErrID, id)
+	}
+	if _, err := strconv.ParseUint(serial, 16, 48); err != nil {
+		return 0, fmt.Errorf("%w: %v", ErrID, id)
+	}
+	f, err := strconv.ParseUint(family, 16, 8)
+	if err != nil || !ds18b20.Family(f).Supported() {
+		return 0, fmt.Errorf("%w: %v", ErrID, id)
+	}
+	return ds18b20.Family(f), nil
+}
+
+func (b *Bus) Path() string {
+	return b.root
+}
+
+// ReadDir lists sensors and single bus master, the same way w1 sysfs does
+func (b *Bus) ReadDir(dirname string) ([]fs.DirEntry, error) {
+	b.mtx.Lock()
+	defer b.mtx.Unlock()
+
+	dir := strings.TrimRight(dirname, "/")
+	var names []string
+	switch dir {
+	case b.root:
+		names = append(b.ids(), masterName)
+	case b.root + "/" + masterName:
+		names = b.ids()
+	default:
+		return nil, w1.ErrNotExist(dirname)
+	}
+	sort.Strings(names)
+
+	entries := make([]fs.DirEntry, len(names))
+	for i, name := range names {
+		entries[i] = w1.DirEntry(name)
+	}
+	return entries, nil
+}
+
+func (b *Bus) ids() []string {
+	ids := make([]string, 0, len(b.sensors))
+	for id := range b.sensors {
+		ids = append(ids, id)
+	}
+	return ids
+}
+
+func (b *Bus) Open(name string) (ds18b20.File, error) {
+	b.mtx.Lock()
+	defer b.mtx.Unlock()
+
+	dir, attr, err := b.split(name)
+	if err != nil {
+		return nil, err
+	}
+	if dir == masterName {
+		if attr != attrBulkRead {
+			return nil, w1.ErrNotExist(name)
+		}
+		// Conversion is instant, there is never anything in progress
+		return w1.NewReader("0"), nil
+	}
+
+	s, ok := b.sensors[dir]
+	if !ok {
+		return nil, w1.ErrNotExist(name)
+	}
+	var content string
+	switch attr {
+	case attrTemperature:
+		if content, err = s.temperature(b.elapsed()); err != nil {
+			return nil, fmt.Errorf("%v: %w", dir, err)
+		}
+	case attrW1Slave:
+		content = s.w1Slave(b.elapsed())
+	case attrResolution:
+		if !s.hasResolution() {
+			return nil, w1.ErrNotExist(name)
+		}
+		content = strconv.Itoa(s.ram.resolution)
+	case attrAlarms:
+		content = fmt.Sprintf("%d %d", s.ram.low, s.ram.high)
+	case attrExtPower:
+		content = "1"
+	default:
+		return nil, w1.ErrNotExist(name)
+	}
+	return w1.NewReader(content), nil
+}
+
+// OpenFile opens attribute for writing, value is applied on Close
+func (b *Bus) OpenFile(name string, flag int, _ fs.FileMode) (ds18b20.File, error) {
+	if flag&(os.O_WRONLY|os.O_RDWR) == 0 {
+		return b.Open(name)
+	}
+
+	b.mtx.Lock()
+	defer b.mtx.Unlock()
+
+	dir, attr, err := b.split(name)
+	if err != nil {
+		return nil, err
+	}
+	if dir == masterName {
+		if attr != attrBulkRead {
+			return nil, w1.ErrNotExist(name)
+		}
+		return w1.NewWriter(func(value string) error {
+			if value != "trigger" {
+				return fmt.Errorf("%w: %v %q", ErrAttr, attr, value)
+			}
+			return nil
+		}), nil
+	}
+
+	s, ok := b.sensors[dir]
+	if !ok {
+		return nil, w1.ErrNotExist(name)
+	}
+	var store func(*sensor, string) error
+	switch attr {
+	case attrResolution:
+		if !s.hasResolution() {
+			return nil, w1.ErrNotExist(name)
+		}
+		store = (*sensor).setResolution
+	case attrAlarms:
+		store = (*sensor).setAlarms
+	case attrEEPROMCmd:
+		store = (*sensor).eepromCmd
+	default:
+		return nil, w1.ErrNotExist(name)
+	}
+	return w1.NewWriter(func(value string) error {
+		b.mtx.Lock()
+		defer b.mtx.Unlock()
+		if err := store(s, value); err != nil {
+			return fmt.Errorf("%v: %w", dir, err)
+		}
+		return nil
+	}), nil
+}
+
+// split returns sensor id (or master name) and attribute from path
+func (b *Bus) split(name string) (dir, attr string, err error) {
+	rel := strings.TrimPrefix(name, b.root+"/")
+	if len(rel) == len(name) {
+		return "", "", w1.ErrNotExist(name)
+	}
+	dir, attr, found := strings.Cut(rel, "/")
+	if !found || strings.Contains(attr, "/") {
+		return "", "", w1.ErrNotExist(name)
+	}
+	return dir, attr, nil
+}
+
+func (b *Bus) elapsed() time.Duration {
+	return b.clock().Sub(b.start)
+}
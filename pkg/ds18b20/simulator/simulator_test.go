@@ -0,0 +1,147 @@
+package simulator_test
+
+import (
+	"github.com/a-clap/iot/pkg/ds18b20"
+	"github.com/a-clap/iot/pkg/ds18b20/simulator"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+type clock struct {
+	now time.Time
+}
+
+func (c *clock) Now() time.Time {
+	return c.now
+}
+
+func TestBus_Add(t *testing.T) {
+	r := require.New(t)
+	bus := simulator.New()
+
+	r.ErrorIs(bus.Add("28-123", simulator.Constant(0)), simulator.ErrID)
+	r.ErrorIs(bus.Add("99-05169397aeff", simulator.Constant(0)), simulator.ErrID)
+	r.Nil(bus.Add("28-05169397aeff", simulator.Constant(0)))
+	r.ErrorIs(bus.Add("28-05169397aeff", simulator.Constant(0)), simulator.ErrExists)
+	r.Nil(bus.Add("10-000000000001", simulator.Constant(0)))
+
+	h := ds18b20.New(bus)
+	ids, err := h.IDs()
+	r.Nil(err)
+	r.Equal([]string{"10-000000000001", "28-05169397aeff"}, ids)
+
+	masters, err := h.Masters()
+	r.Nil(err)
+	r.Equal([]ds18b20.Master{{Name: "w1_bus_master1", IDs: ids}}, masters)
+
+	r.Nil(bus.Remove("10-000000000001"))
+	r.NotNil(bus.Remove("10-000000000001"))
+	ids, err = h.IDs()
+	r.Nil(err)
+	r.Equal([]string{"28-05169397aeff"}, ids)
+}
+
+func TestBus_Temperature(t *testing.T) {
+	r := require.New(t)
+	c := &clock{now: time.Now()}
+	bus := simulator.New(simulator.Clock(c.Now))
+
+	const id = "28-000000000001"
+	r.Nil(bus.Add(id, simulator.Dropouts(simulator.Ramp(20000, 30000, 10*time.Second), 10*time.Second, time.Second)))
+	s, err := ds18b20.New(bus).NewSensor(id)
+	r.Nil(err)
+
+	value, err := s.Value()
+	r.Nil(err)
+	r.EqualValues(20000, value)
+
+	c.now = c.now.Add(5 * time.Second)
+	value, err = s.Value()
+	r.Nil(err)
+	r.EqualValues(25000, value)
+
+	// Quantized to sensor resolution
+	r.Nil(s.SetResolution(ds18b20.Resolution9Bit))
+	c.now = c.now.Add(2*time.Second + 100*time.Millisecond)
+	value, err = s.Value()
+	r.Nil(err)
+	r.EqualValues(27000, value)
+
+	c.now = c.now.Add(2 * time.Second)
+	_, err = s.Value()
+	r.ErrorIs(err, ds18b20.ErrDisconnected)
+
+	r.Nil(bus.SetScript(id, simulator.CRCFailures(simulator.Constant(0), time.Minute, time.Minute)))
+	_, err = s.Value()
+	r.ErrorIs(err, ds18b20.ErrCRC)
+}
+
+func TestBus_NegativeTemperature(t *testing.T) {
+	r := require.New(t)
+	bus := simulator.New()
+	r.Nil(bus.Add("28-000000000001", simulator.Constant(-10125)))
+	r.Nil(bus.Add("10-000000000002", simulator.Constant(-10125)))
+
+	h := ds18b20.New(bus)
+	for _, id := range []string{"28-000000000001", "10-000000000002"} {
+		s, err := h.NewSensor(id)
+		r.Nil(err)
+		value, err := s.Value()
+		r.Nil(err)
+		r.EqualValues(-10125, value, id)
+	}
+}
+
+func TestBus_Settings(t *testing.T) {
+	r := require.New(t)
+	bus := simulator.New()
+	const id = "28-000000000001"
+	r.Nil(bus.Add(id, simulator.Constant(80000)))
+	h := ds18b20.New(bus)
+
+	s, err := h.NewSensor(id)
+	r.Nil(err)
+	info, err := s.Info()
+	r.Nil(err)
+	r.Equal(ds18b20.Info{
+		ID:         id,
+		Family:     ds18b20.DS18B20,
+		Master:     "w1_bus_master1",
+		Resolution: ds18b20.Resolution12Bit,
+		PowerMode:  ds18b20.PowerExternal,
+		AlarmLow:   70,
+		AlarmHigh:  75,
+	}, info)
+
+	alarming, err := h.AlarmingSensors()
+	r.Nil(err)
+	r.Equal([]string{id}, alarming)
+
+	r.Nil(s.SaveEEPROM())
+	r.Nil(s.SetAlarms(0, 100))
+	alarming, err = h.AlarmingSensors()
+	r.Nil(err)
+	r.Empty(alarming)
+
+	r.Nil(s.RestoreEEPROM())
+	low, high, err := s.Alarms()
+	r.Nil(err)
+	r.Equal([]int{70, 75}, []int{low, high})
+}
+
+func TestBus_BulkRead(t *testing.T) {
+	r := require.New(t)
+	bus := simulator.New()
+	r.Nil(bus.Add("28-000000000001", simulator.Constant(1000)))
+	r.Nil(bus.Add("28-000000000002", simulator.Constant(2000)))
+
+	readings, err := ds18b20.New(bus).BulkRead()
+	r.Nil(err)
+	r.Len(readings, 2)
+	for i, reading := range readings {
+		value, _, err := reading.Value()
+		r.Nil(err)
+		r.EqualValues((i+1)*1000, value)
+	}
+}
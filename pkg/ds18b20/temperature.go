@@ -0,0 +1,33 @@
+package ds18b20
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// Millicelsius is a temperature in thousandths of degree Celsius, the same unit kernel driver uses
+type Millicelsius int32
+
+// Celsius returns temperature in degrees Celsius
+func (m Millicelsius) Celsius() float32 {
+	return float32(m) / 1000
+}
+
+// String formats temperature in degrees Celsius with three decimal places, e.g. "-1.250"
+func (m Millicelsius) String() string {
+	sign := ""
+	value := int64(m)
+	if value < 0 {
+		sign = "-"
+		value = -value
+	}
+	return fmt.Sprintf("%s%d.%03d", sign, value/1000, value%1000)
+}
+
+func parseMillicelsius(s string) (Millicelsius, error) {
+	value, err := strconv.ParseInt(s, 10, 32)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %v", ErrInterface, err)
+	}
+	return Millicelsius(value), nil
+}
@@ -0,0 +1,59 @@
+package ds18b20
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// w1Slave is a parsed content of w1_slave attribute:
+//
+//	72 01 4b 46 7f ff 0e 10 57 : crc=57 YES
+//	72 01 4b 46 7f ff 0e 10 57 t=23125
+type w1Slave struct {
+	scratchpad  [9]byte
+	temperature Millicelsius
+}
+
+func parseW1Slave(content string) (w1Slave, error) {
+	var w w1Slave
+	lines := strings.Split(strings.TrimSpace(content), "\n")
+	if len(lines) != 2 {
+		return w, fmt.Errorf("%w: unexpected w1_slave content %q", ErrInterface, content)
+	}
+	crcLine := strings.TrimSpace(lines[0])
+	tempLine := strings.TrimSpace(lines[1])
+
+	fields := strings.Fields(crcLine)
+	if len(fields) != len(w.scratchpad)+3 || !strings.HasPrefix(fields[len(w.scratchpad)+1], "crc=") {
+		return w, fmt.Errorf("%w: unexpected w1_slave crc line %q", ErrInterface, crcLine)
+	}
+	for i := range w.scratchpad {
+		b, err := strconv.ParseUint(fields[i], 16, 8)
+		if err != nil {
+			return w, fmt.Errorf("%w: %v", ErrInterface, err)
+		}
+		w.scratchpad[i] = byte(b)
+	}
+	if fields[len(fields)-1] != "YES" {
+		return w, fmt.Errorf("%w: %s", ErrCRC, crcLine)
+	}
+
+	pos := strings.LastIndex(tempLine, "t=")
+	if pos == -1 {
+		return w, fmt.Errorf("%w: unexpected w1_slave temperature line %q", ErrInterface, tempLine)
+	}
+	var err error
+	w.temperature, err = parseMillicelsius(tempLine[pos+len("t="):])
+	return w, err
+}
+
+// empty is true, when whole scratchpad is zeroed - which happens when sensor doesn't respond
+func (w w1Slave) empty() bool {
+	for _, b := range w.scratchpad {
+		if b != 0 {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,108 @@
+package ds18b20
+
+import (
+	"context"
+	"sort"
+	"time"
+)
+
+type EventType int
+
+const (
+	Added EventType = iota
+	Removed
+)
+
+// Event is emitted by Watch, when sensor appears or disappears from the bus
+type Event struct {
+	Type EventType
+	ID   string
+}
+
+func (e EventType) String() string {
+	switch e {
+	case Added:
+		return "added"
+	case Removed:
+		return "removed"
+	default:
+		return "unknown"
+	}
+}
+
+// Watch rescans bus every interval and emits Event for each sensor, which was plugged in or removed.
+// Sensors present on the bus, when Watch is called, are reported as Added.
+// Returned channel is closed, when ctx is done. Errors on rescan are ignored - bus state is compared on next successful scan.
+func (h *Handler) Watch(ctx context.Context, interval time.Duration) (<-chan Event, error) {
+	if interval <= 0 {
+		return nil, ErrWrongInterval
+	}
+	ids, err := h.readIDs()
+	if err != nil {
+		return nil, err
+	}
+
+	events := make(chan Event)
+	go h.watch(ctx, interval, ids, events)
+
+	return events, nil
+}
+
+func (h *Handler) watch(ctx context.Context, interval time.Duration, current []string, events chan Event) {
+	defer close(events)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	known := make(map[string]struct{})
+	for {
+		for _, e := range diffIDs(known, current) {
+			select {
+			case <-ctx.Done():
+				return
+			case events <- e:
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			ids, err := h.readIDs()
+			if err != nil {
+				// Bus may be temporarily unavailable, keep last known state
+				continue
+			}
+			current = ids
+		}
+	}
+}
+
+// diffIDs updates known to match current and returns events describing this change
+func diffIDs(known map[string]struct{}, current []string) []Event {
+	var events []Event
+	present := make(map[string]struct{}, len(current))
+	for _, id := range current {
+		present[id] = struct{}{}
+		if _, ok := known[id]; !ok {
+			known[id] = struct{}{}
+			events = append(events, Event{Type: Added, ID: id})
+		}
+	}
+
+	for id := range known {
+		if _, ok := present[id]; !ok {
+			delete(known, id)
+			events = append(events, Event{Type: Removed, ID: id})
+		}
+	}
+
+	sort.Slice(events, func(i, j int) bool {
+		if events[i].Type != events[j].Type {
+			return events[i].Type > events[j].Type
+		}
+		return events[i].ID < events[j].ID
+	})
+
+	return events
+}
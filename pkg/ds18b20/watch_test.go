@@ -0,0 +1,90 @@
+package ds18b20_test
+
+import (
+	"context"
+	"github.com/a-clap/iot/pkg/ds18b20"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestHandler_IDsRepeated(t *testing.T) {
+	af := afero.Afero{Fs: afero.NewMemMapFs()}
+	defer func() { _ = af.RemoveAll("") }()
+
+	ids := []string{"28-05169397aeff", "28-0516939fffff"}
+	for _, id := range ids {
+		require.Nil(t, af.Mkdir("/bus/"+id, 0777))
+	}
+	h := ds18b20.New(&iAfero{path: "/bus", a: afero.NewIOFS(af)})
+	for i := 0; i < 3; i++ {
+		got, err := h.IDs()
+		require.Nil(t, err)
+		require.EqualValues(t, ids, got)
+	}
+}
+
+func TestHandler_Watch(t *testing.T) {
+	af := afero.Afero{Fs: afero.NewMemMapFs()}
+	defer func() { _ = af.RemoveAll("") }()
+
+	const bus = "/bus"
+	first, second := "28-05169397aeff", "28-0516939fffff"
+	require.Nil(t, af.Mkdir(bus+"/"+first, 0777))
+	_, err := af.Create(bus + "/w1_bus_master1")
+	require.Nil(t, err)
+
+	h := ds18b20.New(&iAfero{path: bus, a: afero.NewIOFS(af)})
+
+	interval := 5 * time.Millisecond
+	_, err = h.Watch(context.Background(), 0)
+	require.ErrorIs(t, err, ds18b20.ErrWrongInterval)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	events, err := h.Watch(ctx, interval)
+	require.Nil(t, err)
+
+	expect := func(e ds18b20.Event) {
+		select {
+		case got := <-events:
+			require.Equal(t, e, got)
+		case <-time.After(20 * interval):
+			require.Fail(t, "waiting for event too long", "expected %v", e)
+		}
+	}
+	// Sensor present from the beginning
+	expect(ds18b20.Event{Type: ds18b20.Added, ID: first})
+
+	// Plug in
+	require.Nil(t, af.Mkdir(bus+"/"+second, 0777))
+	expect(ds18b20.Event{Type: ds18b20.Added, ID: second})
+
+	// Yank both
+	require.Nil(t, af.RemoveAll(bus+"/"+first))
+	require.Nil(t, af.RemoveAll(bus+"/"+second))
+	expect(ds18b20.Event{Type: ds18b20.Removed, ID: first})
+	expect(ds18b20.Event{Type: ds18b20.Removed, ID: second})
+
+	// Nothing changes, nothing should be reported
+	select {
+	case e := <-events:
+		require.Fail(t, "unexpected event", "%v", e)
+	case <-time.After(3 * interval):
+	}
+
+	cancel()
+	select {
+	case _, ok := <-events:
+		require.False(t, ok)
+	case <-time.After(20 * interval):
+		require.Fail(t, "channel should be closed after cancel")
+	}
+}
+
+func TestHandler_WatchInterfaceError(t *testing.T) {
+	h := ds18b20.New(&iError{})
+	events, err := h.Watch(context.Background(), time.Millisecond)
+	require.Nil(t, events)
+	require.ErrorIs(t, err, ds18b20.ErrInterface)
+}
@@ -0,0 +1,177 @@
+package ds2482
+
+import (
+	"errors"
+	"fmt"
+	"github.com/a-clap/iot/pkg/ds18b20"
+	"io"
+	"sync"
+)
+
+// DS2482 commands
+const (
+	cmdDeviceReset    = 0xF0
+	cmdSetReadPointer = 0xE1
+	cmdWriteConfig    = 0xD2
+	cmdChannelSelect  = 0xC3
+	cmdOneWireReset   = 0xB4
+	cmdOneWireBit     = 0x87
+	cmdOneWireWrite   = 0xA5
+	cmdOneWireRead    = 0x96
+	cmdOneWireTriplet = 0x78
+)
+
+// DS2482 registers, used with cmdSetReadPointer
+const (
+	regStatus  = 0xF0
+	regData    = 0xE1
+	regChannel = 0xD2
+	regConfig  = 0xC3
+)
+
+// Status register bits
+const (
+	status1WB = 1 << iota
+	statusPPD
+	statusSD
+	statusLL
+	statusRST
+	statusSBR
+	statusTSB
+	statusDIR
+)
+
+// Configuration register bits
+const (
+	configAPU = 1 << 0
+	// configSPU turns on strong pullup after the next byte, parasite powered devices draw current from it
+	configSPU = 1 << 2
+)
+
+// DefaultAddress is I2C address of DS2482 with AD pins tied low
+const DefaultAddress = 0x18
+
+var (
+	ErrInterface  = errors.New("error on interface usage")
+	ErrReset      = errors.New("device reset failed")
+	ErrConfig     = errors.New("configuration readback mismatch")
+	ErrTimeout    = errors.New("1-Wire operation timeout")
+	ErrNoPresence = errors.New("no presence pulse on 1-Wire")
+	ErrShort      = errors.New("1-Wire short detected")
+	ErrChannel    = errors.New("wrong channel")
+	ErrSearch     = errors.New("search failed")
+)
+
+// I2C is a transfer to DS2482, write w and then read len(r) bytes
+type I2C interface {
+	io.Closer
+	Tx(w, r []byte) error
+}
+
+// Channels is a number of 1-Wire channels of DS2482: 1 for DS2482-100, 8 for DS2482-800
+type Channels int
+
+// Root is a virtual path, under which Master exposes its devices
+type Root string
+
+const (
+	DS2482x100 Channels = 1
+	DS2482x800 Channels = 8
+)
+
+// Master is a userspace 1-Wire master driving DS2482 over I2C.
+// It implements ds18b20.Onewire, so it can be used directly with ds18b20.Handler.
+type Master struct {
+	I2C
+	mtx      sync.Mutex
+	root     string
+	channels Channels
+	channel  int
+	// devices maps sensor id to channel, filled on every search
+	devices map[string]int
+	// pending holds sensors, which were converted by bulk read, but not read yet
+	pending map[string]struct{}
+	// power holds whether device is parasite powered, read once per device
+	power map[string]bool
+}
+
+var _ ds18b20.Onewire = &Master{}
+var _ ds18b20.AlarmSearcher = &Master{}
+
+// NewDefault opens I2C bus (e.g. "/dev/i2c-0") and creates Master on DS2482 with addr
+func NewDefault(bus string, addr uint16, args ...any) (*Master, error) {
+	dev, err := newI2C(bus, addr)
+	if err != nil {
+		return nil, err
+	}
+	m, err := New(dev, args...)
+	if err != nil {
+		_ = dev.Close()
+		return nil, err
+	}
+	return m, nil
+}
+
+// New resets DS2482 and configures it with active pullup
+func New(i I2C, args ...any) (*Master, error) {
+	m := &Master{
+		I2C:      i,
+		root:     "/ds2482",
+		channels: DS2482x100,
+		channel:  -1,
+		devices:  make(map[string]int),
+		pending:  make(map[string]struct{}),
+		power:    make(map[string]bool),
+	}
+	m.parse(args...)
+
+	if err := m.reset(); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (m *Master) parse(args ...any) {
+	for _, arg := range args {
+		switch arg := arg.(type) {
+		case Channels:
+			m.channels = arg
+		case Root:
+			m.root = string(arg)
+		}
+	}
+}
+
+// reset does device reset and writes configuration
+func (m *Master) reset() error {
+	if err := m.tx([]byte{cmdDeviceReset}, nil); err != nil {
+		return err
+	}
+	status, err := m.status()
+	if err != nil {
+		return err
+	}
+	if status&statusRST == 0 {
+		return fmt.Errorf("%w: status %#x", ErrReset, status)
+	}
+	return m.writeConfig(configAPU)
+}
+
+// writeConfig writes configuration, upper nibble has to be one's complement of lower nibble
+func (m *Master) writeConfig(config byte) error {
+	r := make([]byte, 1)
+	if err := m.tx([]byte{cmdWriteConfig, config | ^config<<4}, r); err != nil {
+		return err
+	}
+	if r[0] != config {
+		return fmt.Errorf("%w: wrote %#x, read %#x", ErrConfig, config, r[0])
+	}
+	return nil
+}
+
+func (m *Master) tx(w, r []byte) error {
+	if err := m.I2C.Tx(w, r); err != nil {
+		return fmt.Errorf("%w: %v", ErrInterface, err)
+	}
+	return nil
+}
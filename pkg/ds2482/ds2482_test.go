@@ -0,0 +1,290 @@
+package ds2482_test
+
+import (
+	"errors"
+	"github.com/a-clap/iot/pkg/ds18b20"
+	"github.com/a-clap/iot/pkg/ds2482"
+	"github.com/stretchr/testify/require"
+	"os"
+	"sort"
+	"testing"
+	"time"
+)
+
+func TestNew(t *testing.T) {
+	r := require.New(t)
+
+	_, err := ds2482.New(newBridge(1))
+	r.Nil(err)
+
+	b := newBridge(1)
+	b.badCfg = true
+	_, err = ds2482.New(b)
+	r.ErrorIs(err, ds2482.ErrConfig)
+
+	b = newBridge(1)
+	b.txErr = errors.New("nack")
+	_, err = ds2482.New(b)
+	r.ErrorIs(err, ds2482.ErrInterface)
+}
+
+func TestROM_ID(t *testing.T) {
+	rom := ds2482.ROM{0x28, 0xff, 0xae, 0x97, 0x93, 0x16, 0x05, 0x00}
+	require.Equal(t, "28-05169397aeff", rom.ID())
+}
+
+func TestMaster_IDs(t *testing.T) {
+	r := require.New(t)
+	sensors := []*thermometer{
+		newThermometer(0x28, 0x05169397aeff, 0),
+		newThermometer(0x28, 0x05169397ae00, 0),
+		newThermometer(0x22, 0x0000000001a5, 0),
+		newThermometer(0x10, 0x123456789abc, 0),
+	}
+	var expected []string
+	for _, s := range sensors {
+		expected = append(expected, s.id())
+	}
+	sort.Strings(expected)
+
+	m, err := ds2482.New(newBridge(1, sensors...))
+	r.Nil(err)
+	ids, err := ds18b20.New(m).IDs()
+	r.Nil(err)
+	r.Equal(expected, ids)
+
+	// Empty bus doesn't respond with presence pulse
+	m, err = ds2482.New(newBridge(1))
+	r.Nil(err)
+	ids, err = ds18b20.New(m).IDs()
+	r.Nil(err)
+	r.Empty(ids)
+}
+
+func TestMaster_Temperature(t *testing.T) {
+	r := require.New(t)
+	sensors := []*thermometer{
+		newThermometer(0x28, 0x01, 25125),
+		newThermometer(0x28, 0x02, -10125),
+		newThermometer(0x10, 0x03, 25500),
+	}
+	expected := []ds18b20.Millicelsius{25125, -10125, 25500}
+
+	m, err := ds2482.New(newBridge(1, sensors...))
+	r.Nil(err)
+	h := ds18b20.New(m)
+	for i, s := range sensors {
+		sensor, err := h.NewSensor(s.id())
+		r.Nil(err, s.id())
+		value, err := sensor.Value()
+		r.Nil(err, s.id())
+		r.Equal(expected[i], value, s.id())
+	}
+
+	// Corrupted scratchpad
+	sensor, err := h.NewSensor(sensors[0].id())
+	r.Nil(err)
+	sensors[0].badCRC = true
+	_, err = sensor.Value()
+	r.ErrorIs(err, ds18b20.ErrCRC)
+
+	// Sensor unplugged
+	_, err = h.NewSensor("28-0000000000ff")
+	r.NotNil(err)
+}
+
+func TestMaster_Settings(t *testing.T) {
+	r := require.New(t)
+	therm := newThermometer(0x28, 0x01, 21937)
+	therm.parasitic = true
+
+	m, err := ds2482.New(newBridge(1, therm))
+	r.Nil(err)
+	sensor, err := ds18b20.New(m).NewSensor(therm.id())
+	r.Nil(err)
+
+	res, err := sensor.Resolution()
+	r.Nil(err)
+	r.Equal(ds18b20.Resolution12Bit, res)
+
+	r.Nil(sensor.SetResolution(ds18b20.Resolution9Bit))
+	res, err = sensor.Resolution()
+	r.Nil(err)
+	r.Equal(ds18b20.Resolution9Bit, res)
+	value, err := sensor.Value()
+	r.Nil(err)
+	r.EqualValues(21500, value)
+
+	r.Nil(sensor.SaveEEPROM())
+	r.Nil(sensor.SetAlarms(-10, 30))
+	low, high, err := sensor.Alarms()
+	r.Nil(err)
+	r.Equal([]int{-10, 30}, []int{low, high})
+
+	r.Nil(sensor.RestoreEEPROM())
+	low, high, err = sensor.Alarms()
+	r.Nil(err)
+	r.Equal([]int{70, 75}, []int{low, high})
+	res, err = sensor.Resolution()
+	r.Nil(err)
+	r.Equal(ds18b20.Resolution9Bit, res)
+
+	mode, err := sensor.PowerMode()
+	r.Nil(err)
+	r.Equal(ds18b20.PowerParasitic, mode)
+}
+
+func TestMaster_AlarmSearch(t *testing.T) {
+	r := require.New(t)
+	cold := newThermometer(0x28, 0x01, 10000)
+	hot := newThermometer(0x28, 0x02, 80000)
+	warm := newThermometer(0x28, 0x03, 72000)
+
+	m, err := ds2482.New(newBridge(1, cold, hot, warm))
+	r.Nil(err)
+	h := ds18b20.New(m)
+
+	// Nothing converted yet
+	ids, err := h.AlarmingSensors()
+	r.Nil(err)
+	r.Empty(ids)
+
+	_, err = h.BulkRead()
+	r.Nil(err)
+	ids, err = h.AlarmingSensors()
+	r.Nil(err)
+	sort.Strings(ids)
+	r.Equal([]string{cold.id(), hot.id()}, ids)
+}
+
+func TestMaster_BulkRead(t *testing.T) {
+	r := require.New(t)
+	sensors := []*thermometer{
+		newThermometer(0x28, 0x01, 1000),
+		newThermometer(0x28, 0x02, 2000),
+	}
+	b := newBridge(1, sensors...)
+	m, err := ds2482.New(b)
+	r.Nil(err)
+
+	readings, err := ds18b20.New(m).BulkRead()
+	r.Nil(err)
+	r.Len(readings, 2)
+	for _, reading := range readings {
+		_, _, err := reading.Value()
+		r.Nil(err)
+	}
+	// Single conversion is started with Skip ROM, sensors aren't read before it
+	r.Equal(1, b.conversions)
+}
+
+func TestMaster_Channels(t *testing.T) {
+	r := require.New(t)
+	first := newThermometer(0x28, 0x01, 1000)
+	second := newThermometer(0x28, 0x02, 2000)
+	b := newBridge(8, first)
+	b.channels[3] = []*thermometer{second}
+
+	m, err := ds2482.New(b, ds2482.DS2482x800)
+	r.Nil(err)
+	h := ds18b20.New(m)
+
+	masters, err := h.Masters()
+	r.Nil(err)
+	r.Len(masters, 8)
+	r.Equal("w1_bus_master1", masters[0].Name)
+	r.Equal([]string{first.id()}, masters[0].IDs)
+	r.Equal("w1_bus_master4", masters[3].Name)
+	r.Equal([]string{second.id()}, masters[3].IDs)
+	r.Empty(masters[1].IDs)
+
+	for _, therm := range []*thermometer{first, second} {
+		sensor, err := h.NewSensor(therm.id())
+		r.Nil(err)
+		value, err := sensor.Value()
+		r.Nil(err)
+		r.EqualValues(therm.temp, value)
+	}
+}
+
+func TestMaster_ChannelSearch(t *testing.T) {
+	r := require.New(t)
+	b := newBridge(8)
+	var sensors []*thermometer
+	for i := range b.channels {
+		therm := newThermometer(0x28, uint64(i+1), 1000*(i+1))
+		b.channels[i] = []*thermometer{therm}
+		sensors = append(sensors, therm)
+	}
+	m, err := ds2482.New(b, ds2482.DS2482x800)
+	r.Nil(err)
+
+	// Root searches every channel
+	_, err = m.ReadDir(m.Path())
+	r.Nil(err)
+	r.Equal(8, b.searches)
+
+	// Master directory searches only its channel
+	b.searches = 0
+	entries, err := m.ReadDir(m.Path() + "/w1_bus_master4")
+	r.Nil(err)
+	r.Len(entries, 1)
+	r.Equal(sensors[3].id(), entries[0].Name())
+	r.Equal(1, b.searches)
+
+	// Known sensor and bulk trigger don't search at all
+	b.searches = 0
+	_, err = m.ReadDir(m.Path() + "/" + sensors[3].id())
+	r.Nil(err)
+	f, err := m.OpenFile(m.Path()+"/w1_bus_master4/therm_bulk_read", os.O_WRONLY, 0)
+	r.Nil(err)
+	_, err = f.Write([]byte("trigger"))
+	r.Nil(err)
+	r.Nil(f.Close())
+	r.Zero(b.searches)
+	r.Equal(1, b.conversions)
+}
+
+func TestMaster_Parasitic(t *testing.T) {
+	r := require.New(t)
+	parasitic := newThermometer(0x28, 0x01, 21500)
+	parasitic.parasitic = true
+	// 9-bit resolution keeps conversion short
+	parasitic.scratchpad[4] = 0x1F
+	parasitic.updateCRC()
+	external := newThermometer(0x28, 0x02, 22000)
+	b := newBridge(1, parasitic, external)
+
+	m, err := ds2482.New(b)
+	r.Nil(err)
+	h := ds18b20.New(m)
+
+	sensor, err := h.NewSensor(parasitic.id())
+	r.Nil(err)
+	start := time.Now()
+	value, err := sensor.Value()
+	r.Nil(err)
+	r.EqualValues(21500, value)
+	// Parasite powered device can't report it is busy, the whole conversion is awaited
+	r.GreaterOrEqual(time.Since(start), ds18b20.Resolution9Bit.ConversionTime())
+	r.NotZero(b.pullups)
+	// Strong pullup is turned off after conversion
+	r.EqualValues(1, b.config)
+
+	// Single strong pullup conversion for the whole bus
+	parasitic.temp, external.temp = 23000, 24000
+	pullups := b.pullups
+	readings, err := h.BulkRead()
+	r.Nil(err)
+	r.Len(readings, 2)
+	for _, reading := range readings {
+		value, _, err := reading.Value()
+		r.Nil(err)
+		r.Contains([]ds18b20.Millicelsius{23000, 24000}, value)
+	}
+	r.Equal(pullups+1, b.pullups)
+
+	r.Nil(sensor.SetAlarms(-10, 30))
+	r.Nil(sensor.SaveEEPROM())
+	r.Equal([3]byte{30, 0xF6, 0x1F}, parasitic.eeprom)
+}
@@ -0,0 +1,365 @@
+package ds2482_test
+
+import (
+	"errors"
+	"github.com/a-clap/iot/internal/w1"
+	"github.com/a-clap/iot/pkg/ds2482"
+)
+
+// thermometer emulates DS18B20 (or DS18S20) on 1-Wire bus
+type thermometer struct {
+	rom  ds2482.ROM
+	temp int // millicelsius
+	// parasitic device browns out on conversion and EEPROM copy without strong pullup,
+	// it also can't hold bus low to report it is busy
+	parasitic  bool
+	badCRC     bool
+	scratchpad [9]byte
+	eeprom     [3]byte
+	alarm      bool
+}
+
+func newThermometer(family byte, serial uint64, temp int) *thermometer {
+	t := &thermometer{temp: temp}
+	t.rom[0] = family
+	for i := 1; i <= 6; i++ {
+		t.rom[i] = byte(serial)
+		serial >>= 8
+	}
+	t.rom[7] = w1.CRC8(t.rom[:7])
+	t.scratchpad = [9]byte{0x50, 0x05, 0x4B, 0x46, 0x7F, 0xFF, 0x0C, 0x10}
+	if family == 0x10 {
+		t.scratchpad = [9]byte{0xAA, 0x00, 0x4B, 0x46, 0xFF, 0xFF, 0x0C, 0x10}
+	}
+	copy(t.eeprom[:], t.scratchpad[2:5])
+	t.updateCRC()
+	return t
+}
+
+func (t *thermometer) id() string {
+	return t.rom.ID()
+}
+
+func (t *thermometer) updateCRC() {
+	t.scratchpad[8] = w1.CRC8(t.scratchpad[:8])
+}
+
+func (t *thermometer) convert() {
+	if t.rom[0] == 0x10 {
+		whole := t.temp / 1000
+		if t.temp < 0 && t.temp%1000 != 0 {
+			whole--
+		}
+		raw := int16(t.temp * 2 / 1000)
+		t.scratchpad[0], t.scratchpad[1] = byte(raw), byte(uint16(raw)>>8)
+		t.scratchpad[6] = byte(16 - (t.temp-whole*1000+250)*16/1000)
+	} else {
+		raw := int16(t.temp * 16 / 1000)
+		bits := int(t.scratchpad[4]>>5&0x3) + 9
+		raw &^= int16(1)<<(12-bits) - 1
+		t.scratchpad[0], t.scratchpad[1] = byte(raw), byte(uint16(raw)>>8)
+	}
+	t.updateCRC()
+
+	integer := t.temp / 1000
+	if t.temp < 0 && t.temp%1000 != 0 {
+		integer--
+	}
+	t.alarm = integer >= int(int8(t.scratchpad[2])) || integer <= int(int8(t.scratchpad[3]))
+}
+
+// brownOut resets temperature register to power-on value 85°C
+func (t *thermometer) brownOut() {
+	t.scratchpad[0], t.scratchpad[1] = 0x50, 0x05
+	if t.rom[0] == 0x10 {
+		t.scratchpad[0], t.scratchpad[1] = 0xAA, 0x00
+	}
+	t.updateCRC()
+}
+
+func (t *thermometer) read() []byte {
+	buf := append([]byte{}, t.scratchpad[:]...)
+	if t.badCRC {
+		buf[8] ^= 0xFF
+	}
+	return buf
+}
+
+func (t *thermometer) scratchpadLen() int {
+	if t.rom[0] == 0x10 {
+		return 2
+	}
+	return 3
+}
+
+// bridge emulates DS2482 registers and 1-Wire bus behind it
+type bridge struct {
+	channels [][]*thermometer
+	channel  int
+	pointer  byte
+	status   byte
+	config   byte
+	data     byte
+	busy     int
+	txErr    error
+	badCfg   bool
+
+	// 1-Wire state after last reset
+	romCmd      byte
+	romBuf      []byte
+	selected    []*thermometer
+	searchBit   int
+	function    byte
+	writeBuf    []byte
+	readBuf     []byte
+	bitReads    int
+	conversions int
+	searches    int
+	// pullups is a number of commands sent with strong pullup
+	pullups int
+}
+
+var channelCodes = map[byte]byte{
+	0xF0: 0xB8, 0xE1: 0xB1, 0xD2: 0xAA, 0xC3: 0xA3, 0xB4: 0x9C, 0xA5: 0x95, 0x96: 0x8E, 0x87: 0x87,
+}
+
+func newBridge(channels int, devices ...*thermometer) *bridge {
+	b := &bridge{channels: make([][]*thermometer, channels)}
+	b.channels[0] = devices
+	return b
+}
+
+func (b *bridge) Close() error {
+	return nil
+}
+
+func (b *bridge) Tx(w, r []byte) error {
+	if b.txErr != nil {
+		return b.txErr
+	}
+	if len(w) > 0 {
+		if err := b.command(w); err != nil {
+			return err
+		}
+	}
+	for i := range r {
+		r[i] = b.register()
+	}
+	return nil
+}
+
+func (b *bridge) command(w []byte) error {
+	switch w[0] {
+	case 0xF0:
+		b.status, b.config, b.channel, b.pointer = 1<<4, 0, 0, 0xF0
+	case 0xE1:
+		b.pointer = w[1]
+	case 0xD2:
+		if w[1]>>4 != ^w[1]&0x0F {
+			return errors.New("config nibbles mismatch")
+		}
+		b.config = w[1] & 0x0F
+		if b.badCfg {
+			b.config = 0
+		}
+		b.status &^= 1 << 4
+		b.pointer = 0xC3
+	case 0xC3:
+		code, ok := channelCodes[w[1]]
+		if !ok || len(b.channels) == 1 {
+			return errors.New("wrong channel code")
+		}
+		for i, c := range []byte{0xF0, 0xE1, 0xD2, 0xC3, 0xB4, 0xA5, 0x96, 0x87} {
+			if c == w[1] {
+				b.channel = i
+			}
+		}
+		b.data, b.pointer = code, 0xD2
+	case 0xB4:
+		b.reset()
+	case 0xA5:
+		b.writeByte(w[1])
+	case 0x96:
+		b.data = b.readByte()
+	case 0x87:
+		b.setBit(0x20, b.readBit())
+	case 0x78:
+		b.triplet(w[1]&0x80 != 0)
+	default:
+		return errors.New("unknown command")
+	}
+	if w[0] >= 0x78 && w[0] <= 0xB4 {
+		b.pointer = 0xF0
+	}
+	if w[0] == 0xB4 {
+		// Reset is the longest operation, status is polled at least once
+		b.busy = 1
+	}
+	return nil
+}
+
+func (b *bridge) register() byte {
+	switch b.pointer {
+	case 0xF0:
+		if b.busy > 0 {
+			b.busy--
+			return b.status | 1
+		}
+		return b.status
+	case 0xC3:
+		return b.config
+	default:
+		return b.data
+	}
+}
+
+func (b *bridge) setBit(mask byte, set bool) {
+	if set {
+		b.status |= mask
+	} else {
+		b.status &^= mask
+	}
+}
+
+func (b *bridge) devices() []*thermometer {
+	return b.channels[b.channel]
+}
+
+func (b *bridge) reset() {
+	b.romCmd, b.romBuf, b.function, b.writeBuf, b.readBuf = 0, nil, 0, nil, nil
+	b.selected = b.devices()
+	b.searchBit = 0
+	b.setBit(1<<1, len(b.devices()) > 0)
+}
+
+func (b *bridge) writeByte(v byte) {
+	switch {
+	case b.romCmd == 0:
+		b.romCmd = v
+		switch v {
+		case 0xF0:
+			b.searches++
+		case 0xCC:
+			b.selected = b.devices()
+		case 0xEC:
+			b.selected = nil
+			for _, t := range b.devices() {
+				if t.alarm {
+					b.selected = append(b.selected, t)
+				}
+			}
+		}
+	case b.romCmd == 0x55 && len(b.romBuf) < 8:
+		b.romBuf = append(b.romBuf, v)
+		if len(b.romBuf) == 8 {
+			b.selected = nil
+			for _, t := range b.devices() {
+				if string(t.rom[:]) == string(b.romBuf) {
+					b.selected = []*thermometer{t}
+				}
+			}
+		}
+	case b.function == 0:
+		b.function = v
+		b.bitReads = 0
+		spu := b.config&(1<<2) != 0
+		if spu {
+			b.pullups++
+		}
+		switch v {
+		case 0x44:
+			b.conversions++
+			for _, t := range b.selected {
+				if t.parasitic && !spu {
+					t.brownOut()
+					continue
+				}
+				t.convert()
+			}
+		case 0xBE:
+			if len(b.selected) == 1 {
+				b.readBuf = b.selected[0].read()
+			}
+		case 0x48:
+			for _, t := range b.selected {
+				if t.parasitic && !spu {
+					continue
+				}
+				copy(t.eeprom[:], t.scratchpad[2:5])
+			}
+		case 0xB8:
+			for _, t := range b.selected {
+				copy(t.scratchpad[2:5], t.eeprom[:])
+				t.updateCRC()
+			}
+		}
+	case b.function == 0x4E:
+		b.writeBuf = append(b.writeBuf, v)
+		for _, t := range b.selected {
+			if len(b.writeBuf) == t.scratchpadLen() {
+				copy(t.scratchpad[2:], b.writeBuf)
+				t.updateCRC()
+			}
+		}
+	}
+}
+
+func (b *bridge) readByte() byte {
+	if len(b.readBuf) == 0 {
+		return 0xFF
+	}
+	v := b.readBuf[0]
+	b.readBuf = b.readBuf[1:]
+	return v
+}
+
+func (b *bridge) readBit() bool {
+	b.bitReads++
+	switch b.function {
+	case 0x44, 0xB8:
+		for _, t := range b.selected {
+			if t.parasitic {
+				// Bus is released, even though device is still busy
+				return true
+			}
+		}
+		// Busy on first time slot
+		return b.bitReads > 1
+	case 0xB4:
+		for _, t := range b.selected {
+			if t.parasitic {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func (b *bridge) triplet(direction bool) {
+	pos, mask := b.searchBit/8, byte(1)<<(b.searchBit%8)
+	id, complement := true, true
+	for _, t := range b.selected {
+		if t.rom[pos]&mask != 0 {
+			complement = false
+		} else {
+			id = false
+		}
+	}
+	taken := direction
+	if id != complement {
+		taken = id
+	} else if id && complement {
+		taken = true
+	}
+	var next []*thermometer
+	for _, t := range b.selected {
+		if (t.rom[pos]&mask != 0) == taken {
+			next = append(next, t)
+		}
+	}
+	b.selected = next
+	b.searchBit++
+	b.setBit(0x20, id)
+	b.setBit(0x40, complement)
+	b.setBit(0x80, taken)
+}
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"github.com/a-clap/iot/pkg/ds18b20"
+	"github.com/a-clap/iot/pkg/ds2482"
+	"github.com/a-clap/logger"
+	"go.uber.org/zap/zapcore"
+	"time"
+)
+
+func main() {
+	log := logger.NewDefaultZap(zapcore.DebugLevel)
+
+	master, err := ds2482.NewDefault("/dev/i2c-1", ds2482.DefaultAddress)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer master.Close()
+
+	ds := ds18b20.New(master)
+
+	// Just to end this after time
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	batches, err := ds.Poll(ctx, nil, time.Second)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	for batch := range batches {
+		for _, readings := range batch {
+			tmp, stamp, err := readings.Get()
+			fmt.Printf("id: %s, Temperature: %s. Time: %s, err: %v \n", readings.ID(), tmp, stamp, err)
+		}
+	}
+
+	fmt.Println("finished")
+}
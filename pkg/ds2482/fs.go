@@ -0,0 +1,223 @@
+package ds2482
+
+import (
+	"fmt"
+	"github.com/a-clap/iot/internal/w1"
+	"github.com/a-clap/iot/pkg/ds18b20"
+	"io/fs"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Attributes exposed the same way as kernel w1_therm does
+const (
+	attrTemperature = "temperature"
+	attrResolution  = "resolution"
+	attrAlarms      = "alarms"
+	attrExtPower    = "ext_power"
+	attrEEPROMCmd   = "eeprom_cmd"
+	attrBulkRead    = "therm_bulk_read"
+	masterPrefix    = "w1_bus_master"
+)
+
+// Thermometer function commands
+const (
+	funcConvert         = 0x44
+	funcReadScratchpad  = 0xBE
+	funcWriteScratchpad = 0x4E
+	funcCopyScratchpad  = 0x48
+	funcRecallEEPROM    = 0xB8
+	funcReadPowerSupply = 0xB4
+)
+
+var (
+	// conversionTimeout is the longest time to wait for conversion or EEPROM recall
+	conversionTimeout = 2 * ds18b20.Resolution12Bit.ConversionTime()
+	// conversionPoll is how often device is asked, whether conversion is finished
+	conversionPoll = 10 * time.Millisecond
+	// eepromWriteTime is time needed by device to copy scratchpad to EEPROM
+	eepromWriteTime = 10 * time.Millisecond
+)
+
+func (m *Master) Path() string {
+	return m.root
+}
+
+// ReadDir lists sensors and masters (one per channel) the way kernel does.
+// Root searches every channel, master directory searches only its own channel.
+func (m *Master) ReadDir(dirname string) ([]fs.DirEntry, error) {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+
+	parts, err := m.split(dirname)
+	if err != nil {
+		return nil, err
+	}
+	if len(parts) > 1 {
+		return nil, w1.ErrNotExist(dirname)
+	}
+	var names []string
+	switch {
+	case len(parts) == 0:
+		if err := m.scan(); err != nil {
+			return nil, err
+		}
+		for id := range m.devices {
+			names = append(names, id)
+		}
+		for channel := 0; channel < int(m.channels); channel++ {
+			names = append(names, masterName(channel))
+		}
+	case strings.HasPrefix(parts[0], masterPrefix):
+		channel, err := m.masterChannel(parts[0])
+		if err != nil {
+			return nil, err
+		}
+		if err := m.scanChannel(channel); err != nil {
+			return nil, err
+		}
+		for id, ch := range m.devices {
+			if ch == channel {
+				names = append(names, id)
+			}
+		}
+	default:
+		if _, ok := m.devices[parts[0]]; !ok {
+			// Device could have been plugged in after last scan
+			if err := m.scan(); err != nil {
+				return nil, err
+			}
+			if _, ok := m.devices[parts[0]]; !ok {
+				return nil, w1.ErrNotExist(dirname)
+			}
+		}
+		names = []string{attrAlarms, attrEEPROMCmd, attrExtPower, attrResolution, attrTemperature}
+	}
+	sort.Strings(names)
+
+	entries := make([]fs.DirEntry, len(names))
+	for i, name := range names {
+		entries[i] = w1.DirEntry(name)
+	}
+	return entries, nil
+}
+
+// Open reads attribute, communication with sensor is done on Open
+func (m *Master) Open(name string) (ds18b20.File, error) {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+
+	dir, attr, err := m.attr(name)
+	if err != nil {
+		return nil, err
+	}
+
+	var content string
+	if strings.HasPrefix(dir, masterPrefix) {
+		if attr != attrBulkRead {
+			return nil, w1.ErrNotExist(name)
+		}
+		content, err = m.bulkState(dir)
+	} else {
+		switch attr {
+		case attrTemperature:
+			content, err = m.temperature(dir)
+		case attrResolution:
+			content, err = m.resolution(dir)
+		case attrAlarms:
+			content, err = m.alarms(dir)
+		case attrExtPower:
+			content, err = m.extPower(dir)
+		default:
+			return nil, w1.ErrNotExist(name)
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	return w1.NewReader(content), nil
+}
+
+// OpenFile opens attribute for writing, data is sent to sensor on Close
+func (m *Master) OpenFile(name string, flag int, _ fs.FileMode) (ds18b20.File, error) {
+	if flag&(os.O_WRONLY|os.O_RDWR) == 0 {
+		return m.Open(name)
+	}
+
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+
+	dir, attr, err := m.attr(name)
+	if err != nil {
+		return nil, err
+	}
+
+	var store func(id, value string) error
+	if strings.HasPrefix(dir, masterPrefix) {
+		if attr != attrBulkRead {
+			return nil, w1.ErrNotExist(name)
+		}
+		store = m.bulkTrigger
+	} else {
+		switch attr {
+		case attrResolution:
+			store = m.setResolution
+		case attrAlarms:
+			store = m.setAlarms
+		case attrEEPROMCmd:
+			store = m.eepromCmd
+		default:
+			return nil, w1.ErrNotExist(name)
+		}
+	}
+	return w1.NewWriter(func(value string) error {
+		m.mtx.Lock()
+		defer m.mtx.Unlock()
+		return store(dir, value)
+	}), nil
+}
+
+// split returns path elements relative to root
+func (m *Master) split(name string) ([]string, error) {
+	rel := strings.TrimPrefix(name, m.root)
+	if len(rel) == len(name) && m.root != "" {
+		return nil, w1.ErrNotExist(name)
+	}
+	rel = strings.Trim(rel, "/")
+	if rel == "" {
+		return nil, nil
+	}
+	return strings.Split(rel, "/"), nil
+}
+
+// attr splits name of attribute into device (sensor or master) and attribute name
+func (m *Master) attr(name string) (dir, attr string, err error) {
+	parts, err := m.split(name)
+	if err != nil {
+		return "", "", err
+	}
+	if len(parts) != 2 {
+		return "", "", w1.ErrNotExist(name)
+	}
+	return parts[0], parts[1], nil
+}
+
+func masterName(channel int) string {
+	return masterPrefix + strconv.Itoa(channel+1)
+}
+
+func (m *Master) masterChannel(name string) (int, error) {
+	n, err := strconv.Atoi(strings.TrimPrefix(name, masterPrefix))
+	if err != nil || n < 1 || n > int(m.channels) {
+		return 0, w1.ErrNotExist(name)
+	}
+	return n - 1, nil
+}
+
+// errorf formats error with sensor id
+func errorf(id string, err error) error {
+	return fmt.Errorf("%v: %w", id, err)
+}
@@ -0,0 +1,42 @@
+package ds2482
+
+import (
+	"periph.io/x/conn/v3/driver/driverreg"
+	"periph.io/x/conn/v3/i2c"
+	"periph.io/x/conn/v3/i2c/i2creg"
+	"periph.io/x/host/v3"
+	"sync"
+)
+
+var (
+	hostInit sync.Once
+	// hostErr is result of the only host initialization, returned to every later caller
+	hostErr error
+)
+
+type i2cDev struct {
+	bus i2c.BusCloser
+	*i2c.Dev
+}
+
+func newI2C(bus string, addr uint16) (*i2cDev, error) {
+	hostInit.Do(func() {
+		if _, hostErr = host.Init(); hostErr != nil {
+			return
+		}
+		_, hostErr = driverreg.Init()
+	})
+	if hostErr != nil {
+		return nil, hostErr
+	}
+
+	b, err := i2creg.Open(bus)
+	if err != nil {
+		return nil, err
+	}
+	return &i2cDev{bus: b, Dev: &i2c.Dev{Addr: addr, Bus: b}}, nil
+}
+
+func (i *i2cDev) Close() error {
+	return i.bus.Close()
+}
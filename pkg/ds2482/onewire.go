@@ -0,0 +1,149 @@
+package ds2482
+
+import (
+	"fmt"
+	"time"
+)
+
+const (
+	// busyPolls is the maximum number of status reads, while waiting for 1-Wire operation
+	busyPolls = 100
+	// busyInterval is the time between status reads, longest 1-Wire operation (reset) takes ~1.2ms
+	busyInterval = 50 * time.Microsecond
+)
+
+// status reads status register, read pointer has to be set to status register
+func (m *Master) status() (byte, error) {
+	r := make([]byte, 1)
+	if err := m.tx(nil, r); err != nil {
+		return 0, err
+	}
+	return r[0], nil
+}
+
+// wait polls status register until 1-Wire is not busy
+func (m *Master) wait() (byte, error) {
+	for i := 0; i < busyPolls; i++ {
+		status, err := m.status()
+		if err != nil {
+			return 0, err
+		}
+		if status&status1WB == 0 {
+			return status, nil
+		}
+		<-time.After(busyInterval)
+	}
+	return 0, ErrTimeout
+}
+
+// selectChannel switches DS2482-800 to channel, it does nothing on single channel DS2482
+func (m *Master) selectChannel(channel int) error {
+	// codes written to select channel, and read back for verification
+	var (
+		selectCodes = [...]byte{0xF0, 0xE1, 0xD2, 0xC3, 0xB4, 0xA5, 0x96, 0x87}
+		readCodes   = [...]byte{0xB8, 0xB1, 0xAA, 0xA3, 0x9C, 0x95, 0x8E, 0x87}
+	)
+	if channel < 0 || channel >= int(m.channels) {
+		return fmt.Errorf("%w: %v", ErrChannel, channel)
+	}
+	if m.channels == DS2482x100 || m.channel == channel {
+		return nil
+	}
+	r := make([]byte, 1)
+	if err := m.tx([]byte{cmdChannelSelect, selectCodes[channel]}, r); err != nil {
+		return err
+	}
+	if r[0] != readCodes[channel] {
+		return fmt.Errorf("%w: selected %v, read %#x", ErrChannel, channel, r[0])
+	}
+	m.channel = channel
+	return nil
+}
+
+// busReset sends reset pulse and checks whether any device responded
+func (m *Master) busReset() error {
+	if err := m.tx([]byte{cmdOneWireReset}, nil); err != nil {
+		return err
+	}
+	status, err := m.wait()
+	if err != nil {
+		return err
+	}
+	if status&statusSD != 0 {
+		return ErrShort
+	}
+	if status&statusPPD == 0 {
+		return ErrNoPresence
+	}
+	return nil
+}
+
+func (m *Master) writeByte(b byte) error {
+	if err := m.tx([]byte{cmdOneWireWrite, b}, nil); err != nil {
+		return err
+	}
+	_, err := m.wait()
+	return err
+}
+
+func (m *Master) write(buf ...byte) error {
+	for _, b := range buf {
+		if err := m.writeByte(b); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (m *Master) readByte() (byte, error) {
+	if err := m.tx([]byte{cmdOneWireRead}, nil); err != nil {
+		return 0, err
+	}
+	if _, err := m.wait(); err != nil {
+		return 0, err
+	}
+	r := make([]byte, 1)
+	if err := m.tx([]byte{cmdSetReadPointer, regData}, r); err != nil {
+		return 0, err
+	}
+	return r[0], nil
+}
+
+func (m *Master) read(buf []byte) error {
+	for i := range buf {
+		b, err := m.readByte()
+		if err != nil {
+			return err
+		}
+		buf[i] = b
+	}
+	return nil
+}
+
+// readBit generates read time slot
+func (m *Master) readBit() (bool, error) {
+	if err := m.tx([]byte{cmdOneWireBit, 0x80}, nil); err != nil {
+		return false, err
+	}
+	status, err := m.wait()
+	if err != nil {
+		return false, err
+	}
+	return status&statusSBR != 0, nil
+}
+
+// triplet reads bit and its complement, then writes direction - single step of search
+func (m *Master) triplet(direction bool) (bit, complement, taken bool, err error) {
+	dir := byte(0)
+	if direction {
+		dir = 0x80
+	}
+	if err = m.tx([]byte{cmdOneWireTriplet, dir}, nil); err != nil {
+		return
+	}
+	status, err := m.wait()
+	if err != nil {
+		return
+	}
+	return status&statusSBR != 0, status&statusTSB != 0, status&statusDIR != 0, nil
+}
@@ -0,0 +1,196 @@
+package ds2482
+
+import (
+	"errors"
+	"fmt"
+	"github.com/a-clap/iot/internal/w1"
+	"strconv"
+	"strings"
+)
+
+// 1-Wire ROM commands
+const (
+	romSearch      = 0xF0
+	romAlarmSearch = 0xEC
+	romMatch       = 0x55
+	romSkip        = 0xCC
+)
+
+// ROM is 64-bit 1-Wire device address: family code, 48-bit serial number and CRC8
+type ROM [8]byte
+
+// ID formats ROM the same way kernel does, e.g. "28-05169397aeff"
+func (r ROM) ID() string {
+	serial := uint64(0)
+	for i := 6; i >= 1; i-- {
+		serial = serial<<8 | uint64(r[i])
+	}
+	return fmt.Sprintf("%02x-%012x", r[0], serial)
+}
+
+// parseROM is reverse of ROM.ID
+func parseROM(id string) (ROM, error) {
+	var r ROM
+	family, serial, found := strings.Cut(id, "-")
+	if !found || len(family) != 2 || len(serial) != 12 {
+		return r, fmt.Errorf("%w: malformed id %v", ErrSearch, id)
+	}
+	f, err := strconv.ParseUint(family, 16, 8)
+	if err != nil {
+		return r, fmt.Errorf("%w: %v", ErrSearch, err)
+	}
+	s, err := strconv.ParseUint(serial, 16, 48)
+	if err != nil {
+		return r, fmt.Errorf("%w: %v", ErrSearch, err)
+	}
+	r[0] = byte(f)
+	for i := 1; i <= 6; i++ {
+		r[i] = byte(s)
+		s >>= 8
+	}
+	r[7] = w1.CRC8(r[:7])
+	return r, nil
+}
+
+// search finds every device on channel, which responds to command (normal or alarm search)
+func (m *Master) search(channel int, command byte) ([]ROM, error) {
+	if err := m.selectChannel(channel); err != nil {
+		return nil, err
+	}
+
+	var (
+		roms            []ROM
+		rom             ROM
+		lastDiscrepancy int
+	)
+	for {
+		if err := m.busReset(); err != nil {
+			if errors.Is(err, ErrNoPresence) {
+				// Nothing on bus
+				return roms, nil
+			}
+			return nil, err
+		}
+		if err := m.writeByte(command); err != nil {
+			return nil, err
+		}
+
+		lastZero := 0
+		for bit := 1; bit <= 64; bit++ {
+			pos, mask := (bit-1)/8, byte(1)<<((bit-1)%8)
+			var direction bool
+			switch {
+			case bit < lastDiscrepancy:
+				direction = rom[pos]&mask != 0
+			case bit == lastDiscrepancy:
+				direction = true
+			}
+
+			id, complement, taken, err := m.triplet(direction)
+			if err != nil {
+				return nil, err
+			}
+			if id && complement {
+				// No device participates, happens on alarm search without alarming devices
+				if bit == 1 {
+					return roms, nil
+				}
+				return nil, fmt.Errorf("%w: devices left bus on bit %v", ErrSearch, bit)
+			}
+			if !id && !complement && !taken {
+				lastZero = bit
+			}
+			if taken {
+				rom[pos] |= mask
+			} else {
+				rom[pos] &^= mask
+			}
+		}
+
+		if w1.CRC8(rom[:7]) != rom[7] {
+			return nil, fmt.Errorf("%w: crc mismatch of %v", ErrSearch, rom.ID())
+		}
+		roms = append(roms, rom)
+
+		lastDiscrepancy = lastZero
+		if lastDiscrepancy == 0 {
+			return roms, nil
+		}
+	}
+}
+
+// scan searches every channel and updates devices
+func (m *Master) scan() error {
+	for channel := 0; channel < int(m.channels); channel++ {
+		if err := m.scanChannel(channel); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// scanChannel searches only channel and updates devices found on it
+func (m *Master) scanChannel(channel int) error {
+	roms, err := m.search(channel, romSearch)
+	if err != nil {
+		return err
+	}
+	found := make(map[string]struct{}, len(roms))
+	for _, rom := range roms {
+		found[rom.ID()] = struct{}{}
+	}
+	for id, ch := range m.devices {
+		if _, ok := found[id]; ch == channel && !ok {
+			// Device could be plugged back powered differently
+			delete(m.devices, id)
+			delete(m.power, id)
+		}
+	}
+	for id := range found {
+		m.devices[id] = channel
+	}
+	return nil
+}
+
+// AlarmSearch returns ids of sensors, which had alarm condition at the last conversion
+func (m *Master) AlarmSearch() ([]string, error) {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+
+	var ids []string
+	for channel := 0; channel < int(m.channels); channel++ {
+		roms, err := m.search(channel, romAlarmSearch)
+		if err != nil {
+			return nil, err
+		}
+		for _, rom := range roms {
+			ids = append(ids, rom.ID())
+		}
+	}
+	return ids, nil
+}
+
+// matchROM resets bus and addresses device with id, following commands will be received only by this device
+func (m *Master) matchROM(id string) error {
+	rom, err := parseROM(id)
+	if err != nil {
+		return err
+	}
+	channel, ok := m.devices[id]
+	if !ok {
+		// Device could have been plugged in after last scan
+		if err := m.scan(); err != nil {
+			return err
+		}
+		if channel, ok = m.devices[id]; !ok {
+			return w1.ErrNotExist(id)
+		}
+	}
+	if err := m.selectChannel(channel); err != nil {
+		return err
+	}
+	if err := m.busReset(); err != nil {
+		return err
+	}
+	return m.write(append([]byte{romMatch}, rom[:]...)...)
+}
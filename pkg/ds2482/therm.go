@@ -0,0 +1,359 @@
+package ds2482
+
+import (
+	"errors"
+	"fmt"
+	"github.com/a-clap/iot/internal/w1"
+	"github.com/a-clap/iot/pkg/ds18b20"
+	"io/fs"
+	"strconv"
+	"time"
+)
+
+const scratchpadLen = 9
+
+// temperature converts temperature (unless bulk read already did it) and returns it in millicelsius
+func (m *Master) temperature(id string) (string, error) {
+	if _, ok := m.pending[id]; ok {
+		delete(m.pending, id)
+	} else if err := m.convert(id); err != nil {
+		return "", err
+	}
+
+	scratchpad, err := m.scratchpad(id)
+	if err != nil {
+		return "", err
+	}
+	rom, _ := parseROM(id)
+	return strconv.FormatInt(toMillicelsius(ds18b20.Family(rom[0]), scratchpad), 10), nil
+}
+
+// convert starts conversion and waits until it is finished
+func (m *Master) convert(id string) error {
+	parasitic, err := m.parasitic(id)
+	if err != nil {
+		return err
+	}
+	if !parasitic {
+		if err := m.matchROM(id); err != nil {
+			return err
+		}
+		if err := m.writeByte(funcConvert); err != nil {
+			return err
+		}
+		return m.waitDone(id)
+	}
+	wait, err := m.conversionTime(id)
+	if err != nil {
+		return err
+	}
+	if err := m.matchROM(id); err != nil {
+		return err
+	}
+	return m.strongPullup(funcConvert, wait)
+}
+
+// strongPullup writes command with strong pullup turned on and keeps it for d.
+// Parasite powered device can't report it is busy, so the whole time is awaited.
+func (m *Master) strongPullup(command byte, d time.Duration) error {
+	if err := m.writeConfig(configAPU | configSPU); err != nil {
+		return err
+	}
+	err := m.writeByte(command)
+	if err == nil {
+		<-time.After(d)
+	}
+	if cfgErr := m.writeConfig(configAPU); err == nil {
+		err = cfgErr
+	}
+	return err
+}
+
+// parasitic checks with Read Power Supply, whether device is parasite powered. It is asked only once.
+func (m *Master) parasitic(id string) (bool, error) {
+	if parasitic, ok := m.power[id]; ok {
+		return parasitic, nil
+	}
+	if err := m.matchROM(id); err != nil {
+		return false, err
+	}
+	if err := m.writeByte(funcReadPowerSupply); err != nil {
+		return false, err
+	}
+	// Parasite powered devices pull bus low during read time slot
+	external, err := m.readBit()
+	if err != nil {
+		return false, err
+	}
+	m.power[id] = !external
+	return !external, nil
+}
+
+// conversionTime returns time needed by device to convert at its current resolution
+func (m *Master) conversionTime(id string) (time.Duration, error) {
+	rom, err := parseROM(id)
+	if err != nil {
+		return 0, err
+	}
+	if ds18b20.Family(rom[0]) == ds18b20.DS18S20 {
+		return ds18b20.Resolution12Bit.ConversionTime(), nil
+	}
+	scratchpad, err := m.scratchpad(id)
+	if err != nil {
+		return 0, err
+	}
+	return toResolution(scratchpad).ConversionTime(), nil
+}
+
+// waitDone polls device with read time slots, device holds bus low until operation is finished
+func (m *Master) waitDone(id string) error {
+	deadline := time.Now().Add(conversionTimeout)
+	for {
+		done, err := m.readBit()
+		if err != nil {
+			return err
+		}
+		if done {
+			return nil
+		}
+		if time.Now().After(deadline) {
+			return errorf(id, ErrTimeout)
+		}
+		<-time.After(conversionPoll)
+	}
+}
+
+// scratchpad reads whole scratchpad and verifies its crc
+func (m *Master) scratchpad(id string) ([scratchpadLen]byte, error) {
+	var buf [scratchpadLen]byte
+	if err := m.matchROM(id); err != nil {
+		return buf, err
+	}
+	if err := m.writeByte(funcReadScratchpad); err != nil {
+		return buf, err
+	}
+	if err := m.read(buf[:]); err != nil {
+		return buf, err
+	}
+	if crc := w1.CRC8(buf[:8]); crc != buf[8] {
+		return buf, fmt.Errorf("%w: %v: expected %#x, got %#x", ds18b20.ErrCRC, id, crc, buf[8])
+	}
+	return buf, nil
+}
+
+// writeScratchpad writes alarm thresholds and configuration register
+func (m *Master) writeScratchpad(id string, high, low int8, config byte) error {
+	if err := m.matchROM(id); err != nil {
+		return err
+	}
+	return m.write(funcWriteScratchpad, byte(high), byte(low), config)
+}
+
+// toMillicelsius converts temperature register, DS18S20 has 0.5°C resolution extended with count remain
+func toMillicelsius(family ds18b20.Family, scratchpad [scratchpadLen]byte) int64 {
+	raw := int64(int16(uint16(scratchpad[1])<<8 | uint16(scratchpad[0])))
+	if family != ds18b20.DS18S20 {
+		return raw * 1000 / 16
+	}
+	countRemain, countPerC := int64(scratchpad[6]), int64(scratchpad[7])
+	temp := (raw>>1)*1000 - 250
+	if countPerC != 0 {
+		temp += 1000 * (countPerC - countRemain) / countPerC
+	}
+	return temp
+}
+
+func (m *Master) resolution(id string) (string, error) {
+	if err := m.hasConfig(id); err != nil {
+		return "", err
+	}
+	scratchpad, err := m.scratchpad(id)
+	if err != nil {
+		return "", err
+	}
+	return strconv.Itoa(int(toResolution(scratchpad))), nil
+}
+
+// toResolution decodes resolution from configuration register
+func toResolution(scratchpad [scratchpadLen]byte) ds18b20.Resolution {
+	return ds18b20.Resolution(scratchpad[4]>>5&0x3) + ds18b20.Resolution9Bit
+}
+
+func (m *Master) setResolution(id, value string) error {
+	if err := m.hasConfig(id); err != nil {
+		return err
+	}
+	bits, err := strconv.Atoi(value)
+	if err != nil || bits < 9 || bits > 12 {
+		return fmt.Errorf("%w: resolution %v", fs.ErrInvalid, value)
+	}
+	scratchpad, err := m.scratchpad(id)
+	if err != nil {
+		return err
+	}
+	config := byte(bits-9)<<5 | 0x1F
+	return m.writeScratchpad(id, int8(scratchpad[2]), int8(scratchpad[3]), config)
+}
+
+// hasConfig checks whether sensor has configuration register, DS18S20 doesn't
+func (m *Master) hasConfig(id string) error {
+	rom, err := parseROM(id)
+	if err != nil {
+		return err
+	}
+	if ds18b20.Family(rom[0]) == ds18b20.DS18S20 {
+		return w1.ErrNotExist(id + "/" + attrResolution)
+	}
+	return nil
+}
+
+func (m *Master) alarms(id string) (string, error) {
+	scratchpad, err := m.scratchpad(id)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%d %d", int8(scratchpad[3]), int8(scratchpad[2])), nil
+}
+
+func (m *Master) setAlarms(id, value string) error {
+	low, high, ok := w1.ParseAlarms(value)
+	if !ok {
+		return fmt.Errorf("%w: alarms %v", fs.ErrInvalid, value)
+	}
+
+	scratchpad, err := m.scratchpad(id)
+	if err != nil {
+		return err
+	}
+	rom, _ := parseROM(id)
+	if ds18b20.Family(rom[0]) == ds18b20.DS18S20 {
+		// DS18S20 has no configuration register, only thresholds are written
+		if err := m.matchROM(id); err != nil {
+			return err
+		}
+		return m.write(funcWriteScratchpad, byte(int8(high)), byte(int8(low)))
+	}
+	return m.writeScratchpad(id, int8(high), int8(low), scratchpad[4])
+}
+
+func (m *Master) extPower(id string) (string, error) {
+	parasitic, err := m.parasitic(id)
+	if err != nil {
+		return "", err
+	}
+	if parasitic {
+		return "0", nil
+	}
+	return "1", nil
+}
+
+func (m *Master) eepromCmd(id, value string) error {
+	var command byte
+	switch value {
+	case "save":
+		command = funcCopyScratchpad
+	case "restore":
+		command = funcRecallEEPROM
+	default:
+		return fmt.Errorf("%w: eeprom command %v", fs.ErrInvalid, value)
+	}
+	parasitic, err := m.parasitic(id)
+	if err != nil {
+		return err
+	}
+	if err := m.matchROM(id); err != nil {
+		return err
+	}
+	if command == funcCopyScratchpad {
+		if parasitic {
+			return m.strongPullup(command, eepromWriteTime)
+		}
+		if err := m.writeByte(command); err != nil {
+			return err
+		}
+		<-time.After(eepromWriteTime)
+		return nil
+	}
+	if err := m.writeByte(command); err != nil {
+		return err
+	}
+	return m.waitDone(id)
+}
+
+// bulkState returns state of bulk conversion on channel the same way kernel does:
+// 1 when there are converted sensors not read yet, 0 otherwise.
+// Conversion is awaited on trigger, so -1 is never returned.
+func (m *Master) bulkState(master string) (string, error) {
+	channel, err := m.masterChannel(master)
+	if err != nil {
+		return "", err
+	}
+	for id := range m.pending {
+		if m.devices[id] == channel {
+			return "1", nil
+		}
+	}
+	return "0", nil
+}
+
+// bulkTrigger starts conversion on every sensor on channel at once (Skip ROM) and waits until it is finished.
+// Bus isn't searched, sensors known from the last scan are marked as converted
+func (m *Master) bulkTrigger(master, value string) error {
+	if value != "trigger" {
+		return fmt.Errorf("%w: %v %v", fs.ErrInvalid, attrBulkRead, value)
+	}
+	channel, err := m.masterChannel(master)
+	if err != nil {
+		return err
+	}
+	// Parasite powered sensors need strong pullup for the longest conversion among them
+	var wait time.Duration
+	for id, ch := range m.devices {
+		if ch != channel {
+			continue
+		}
+		parasitic, err := m.parasitic(id)
+		if err != nil {
+			if errors.Is(err, ErrNoPresence) {
+				return nil
+			}
+			return err
+		}
+		if !parasitic {
+			continue
+		}
+		d, err := m.conversionTime(id)
+		if err != nil {
+			return err
+		}
+		if d > wait {
+			wait = d
+		}
+	}
+	if err := m.selectChannel(channel); err != nil {
+		return err
+	}
+	if err := m.busReset(); err != nil {
+		if errors.Is(err, ErrNoPresence) {
+			return nil
+		}
+		return err
+	}
+	if err := m.writeByte(romSkip); err != nil {
+		return err
+	}
+	if wait > 0 {
+		err = m.strongPullup(funcConvert, wait)
+	} else if err = m.writeByte(funcConvert); err == nil {
+		err = m.waitDone(master)
+	}
+	if err != nil {
+		return err
+	}
+	for id, ch := range m.devices {
+		if ch == channel {
+			m.pending[id] = struct{}{}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,59 @@
+package gpio
+
+import (
+	"errors"
+	"github.com/warthog618/gpiod"
+	"sync"
+)
+
+var (
+	ErrWatching = errors.New("line is already watched")
+)
+
+// FallingEdges watches input for falling edges, e.g. DRDY signal of ADC.
+// Line is requested on Watch and released on Close, so it can be watched many times.
+type FallingEdges struct {
+	mtx     sync.Mutex
+	pin     Pin
+	options []gpiod.LineReqOption
+	line    *gpiod.Line
+}
+
+func NewFallingEdges(pin Pin, options ...gpiod.LineReqOption) *FallingEdges {
+	return &FallingEdges{pin: pin, options: options}
+}
+
+// Watch requests line and calls handler on every falling edge, until Close is called.
+// Handler is called from gpiod goroutine, it shouldn't block.
+func (f *FallingEdges) Watch(handler func()) error {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	if f.line != nil {
+		return ErrWatching
+	}
+
+	options := append(append([]gpiod.LineReqOption{}, f.options...),
+		gpiod.AsInput,
+		gpiod.WithFallingEdge,
+		gpiod.WithEventHandler(func(gpiod.LineEvent) {
+			handler()
+		}))
+	line, err := getLine(f.pin, options...)
+	if err != nil {
+		return err
+	}
+	f.line = line
+	return nil
+}
+
+// Close stops watching and releases line
+func (f *FallingEdges) Close() error {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	if f.line == nil {
+		return nil
+	}
+	err := f.line.Close()
+	f.line = nil
+	return err
+}
@@ -3,18 +3,37 @@ package max31865
 import "sync/atomic"
 
 type Wiring string
+type Filter int
+type Mode int
 type RefRes float32
 type RNominal float32
 type ID string
 
+// LeadResistance is a resistance of both leads in Ω, it is subtracted from measured resistance in 2-wire connection.
+// 3-wire and 4-wire connections compensate leads by themselves, so it is ignored there.
+type LeadResistance float32
+
 const (
 	TwoWire   Wiring = "twoWire"
 	ThreeWire Wiring = "threeWire"
 	FourWire  Wiring = "fourWire"
 )
 
+// Filter selects notch frequency of input filter, it should match mains frequency
+const (
+	Filter50Hz Filter = 50
+	Filter60Hz Filter = 60
+)
+
+const (
+	// Continuous conversion, VBIAS always on
+	Continuous Mode = iota
+	// OneShot conversion on each Temperature call, VBIAS is on only during conversion
+	OneShot
+)
+
 const (
-	filter60Hz uint8 = iota
+	filter50Hz uint8 = iota
 	clearFault
 	faultDetect1
 	faultDetect2
@@ -27,8 +46,8 @@ const (
 type pollType int
 
 const (
-	sync pollType = iota
-	async
+	pollSync pollType = iota
+	pollAsync
 )
 
 type config struct {
@@ -36,9 +55,23 @@ type config struct {
 	wiring   Wiring
 	refRes   RefRes
 	rNominal RNominal
+	leadRes  LeadResistance
 	ready    Ready
 	polling  atomic.Bool
 	pollType pollType
+	filter   Filter
+	mode     Mode
+	// curve describes RTD element, conversion selects whether it is solved directly or by lookup table
+	curve      Curve
+	conversion Conversion
+	// manualDelay is used by manual fault detection
+	manualDelay ManualDelay
+	// thresholds are written only if user set them, otherwise chip defaults (whole range) are kept
+	lowThreshold  *LowThreshold
+	highThreshold *HighThreshold
+	// recovery is used by Poll, when reading fails
+	recovery Recovery
+	events   StateEvents
 }
 
 type regConfig struct {
@@ -47,19 +80,35 @@ type regConfig struct {
 
 func newConfig() config {
 	return config{
-		id:       "",
-		wiring:   ThreeWire,
-		refRes:   430.0,
-		rNominal: 100.0,
-		ready:    nil,
-		polling:  atomic.Bool{},
-		pollType: sync,
+		id:          "",
+		wiring:      ThreeWire,
+		refRes:      0,
+		rNominal:    100.0,
+		ready:       nil,
+		polling:     atomic.Bool{},
+		pollType:    pollSync,
+		filter:      Filter50Hz,
+		mode:        Continuous,
+		curve:       PT385,
+		conversion:  Equation,
+		manualDelay: defaultManualDelay,
+	}
+}
+
+// complete sets values, which depend on other arguments
+func (c *config) complete() {
+	if c.refRes == 0 {
+		// Reference resistor recommended by datasheet: 430Ω for PT100, 4.3kΩ for PT1000
+		c.refRes = RefRes(4.3 * float32(c.rNominal))
+	}
+	if _, ok := c.curve.(Table); !ok && c.conversion == Lookup {
+		c.curve = NewTable(c.curve, lookupMin, lookupMax, lookupStep)
 	}
 }
 
 func newRegConfig() *regConfig {
 	// Default values
-	value := uint8((1 << filter60Hz) | (1 << continuous) | (1 << vBias))
+	value := uint8((1 << filter50Hz) | (1 << continuous) | (1 << vBias))
 
 	c := &regConfig{
 		value: value,
@@ -76,6 +125,35 @@ func (c *regConfig) setWiring(w Wiring) {
 	}
 }
 
+func (c *regConfig) setFilter(f Filter) {
+	const filterMsk = 1 << filter50Hz
+	if f == Filter50Hz {
+		c.value |= filterMsk
+	} else {
+		c.value &^= filterMsk
+	}
+}
+
+// setMode selects conversion mode, in one-shot mode VBIAS is turned on only for conversion
+func (c *regConfig) setMode(m Mode) {
+	const modeMsk = 1<<continuous | 1<<vBias
+	if m == Continuous {
+		c.value |= modeMsk
+	} else {
+		c.value &^= modeMsk
+	}
+}
+
+// biasOn turns VBIAS on, it has to settle before one-shot conversion
+func (c *regConfig) biasOn() uint8 {
+	return c.reg() | 1<<vBias
+}
+
+// oneShot starts single conversion
+func (c *regConfig) oneShot() uint8 {
+	return c.biasOn() | 1<<oneShot
+}
+
 func (c *regConfig) reg() uint8 {
 	return c.value
 }
@@ -85,7 +163,21 @@ func (c *regConfig) clearFaults() uint8 {
 }
 
 func (c *regConfig) faultDetect() uint8 {
-	return 0b10000100 | (c.reg() & ((1 << filter60Hz) | (1 << wire3)))
+	return 0b10000100 | (c.reg() & ((1 << filter50Hz) | (1 << wire3)))
+}
+
+// faultDetectIdle keeps VBIAS on and conversions off, as required before manual fault detection
+func (c *regConfig) faultDetectIdle() uint8 {
+	return 0b10000000 | (c.reg() & ((1 << filter50Hz) | (1 << wire3)))
+}
+
+// faultDetectManual returns value for first (cycle = 1) or second (cycle = 2) step of manual fault detection
+func (c *regConfig) faultDetectManual(cycle int) uint8 {
+	value := c.faultDetectIdle() | 1<<faultDetect2
+	if cycle == 2 {
+		value |= 1 << faultDetect1
+	}
+	return value
 }
 
 func (c *regConfig) faultDetectFinished(reg uint8) bool {
@@ -12,6 +12,9 @@ func TestConfig_reg(t *testing.T) {
 		reg         uint8
 		clearFaults uint8
 		faultDetect uint8
+		idle        uint8
+		cycle1      uint8
+		cycle2      uint8
 	}{
 		{
 			name:        "two wire",
@@ -19,6 +22,9 @@ func TestConfig_reg(t *testing.T) {
 			reg:         0b11000001,
 			clearFaults: 0b11000001 | (1 << 1),
 			faultDetect: 0b10000101,
+			idle:        0b10000001,
+			cycle1:      0b10001001,
+			cycle2:      0b10001101,
 		},
 		{
 			name:        "four wire",
@@ -26,6 +32,9 @@ func TestConfig_reg(t *testing.T) {
 			reg:         0b11000001,
 			clearFaults: 0b11000001 | (1 << 1),
 			faultDetect: 0b10000101,
+			idle:        0b10000001,
+			cycle1:      0b10001001,
+			cycle2:      0b10001101,
 		},
 		{
 			name:        "three wire",
@@ -33,6 +42,9 @@ func TestConfig_reg(t *testing.T) {
 			reg:         0b11010001,
 			clearFaults: 0b11010001 | (1 << 1),
 			faultDetect: 0b10010101,
+			idle:        0b10010001,
+			cycle1:      0b10011001,
+			cycle2:      0b10011101,
 		},
 	}
 	for _, tt := range tests {
@@ -51,6 +63,18 @@ func TestConfig_reg(t *testing.T) {
 			if got := c.faultDetect(); got != tt.faultDetect {
 				t.Errorf("FaultDetect() = %v, faultDetect %v", got, tt.faultDetect)
 			}
+
+			if got := c.faultDetectIdle(); got != tt.idle {
+				t.Errorf("faultDetectIdle() = %v, idle %v", got, tt.idle)
+			}
+
+			if got := c.faultDetectManual(1); got != tt.cycle1 {
+				t.Errorf("faultDetectManual(1) = %v, cycle1 %v", got, tt.cycle1)
+			}
+
+			if got := c.faultDetectManual(2); got != tt.cycle2 {
+				t.Errorf("faultDetectManual(2) = %v, cycle2 %v", got, tt.cycle2)
+			}
 		})
 	}
 }
@@ -78,3 +102,19 @@ func TestConfig_faultDetectFinished(t *testing.T) {
 	})
 
 }
+
+func TestConfig_filterAndMode(t *testing.T) {
+	c := newRegConfig()
+	c.setFilter(Filter60Hz)
+	require.Equal(t, uint8(0b11010000), c.reg())
+	c.setFilter(Filter50Hz)
+	require.Equal(t, uint8(0b11010001), c.reg())
+
+	c.setMode(OneShot)
+	require.Equal(t, uint8(0b00010001), c.reg())
+	require.Equal(t, uint8(0b10010001), c.biasOn())
+	require.Equal(t, uint8(0b10110001), c.oneShot())
+
+	c.setMode(Continuous)
+	require.Equal(t, uint8(0b11010001), c.reg())
+}
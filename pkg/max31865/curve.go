@@ -0,0 +1,206 @@
+package max31865
+
+import (
+	"math"
+	"sort"
+)
+
+// Curve is a resistance-temperature characteristic of RTD element.
+// Resistance is expressed as ratio R(T)/R0, where R0 is RNominal.
+type Curve interface {
+	Ratio(tmp float64) float64
+	Temperature(ratio float64) float64
+}
+
+// Conversion selects, how RTD code is converted to temperature
+type Conversion int
+
+const (
+	// Equation solves Curve for every reading
+	Equation Conversion = iota
+	// Lookup interpolates table, which is built once from Curve
+	Lookup
+)
+
+// Range of temperatures covered by lookup table, it is the range of IEC 60751
+const (
+	lookupMin  = -200.0
+	lookupMax  = 850.0
+	lookupStep = 1.0
+)
+
+// CVD holds coefficients of Callendar-Van Dusen equation:
+//
+//	R(T) = R0(1 + A*T + B*T^2)                    for T >= 0°C
+//	R(T) = R0(1 + A*T + B*T^2 + C*(T-100)*T^3)    for T < 0°C
+type CVD struct {
+	A, B, C float64
+}
+
+// Polynomial describes R(T) = R0(1 + P[0]*T + P[1]*T^2 + ...), it is used for nickel elements
+type Polynomial []float64
+
+var (
+	// PT385 is platinum RTD (α = 0.00385) defined by IEC 60751, default curve
+	PT385 = CVD{A: 3.9083e-3, B: -5.775e-7, C: -4.183e-12}
+	// PT392 is platinum RTD (α = 0.00392), so-called American curve
+	PT392 = CVD{A: 3.9848e-3, B: -5.870e-7, C: -4.0e-12}
+	// Ni120 is nickel RTD (α = 0.00672, Edison curve No. 7), fitted to table at -80, 0, 100 and 260°C
+	Ni120 = Polynomial{5.98954e-3, 6.21203e-6, 1.09261e-8}
+)
+
+const (
+	// newtonIterations is enough for any sane curve to get far below ADC resolution
+	newtonIterations = 20
+	newtonEpsilon    = 1e-9
+)
+
+func (c CVD) Ratio(tmp float64) float64 {
+	r := 1 + c.A*tmp + c.B*tmp*tmp
+	if tmp < 0 {
+		r += c.C * (tmp - 100) * tmp * tmp * tmp
+	}
+	return r
+}
+
+func (c CVD) Temperature(ratio float64) float64 {
+	// Quadratic part is solved directly, it is exact above 0°C
+	var tmp float64
+	if c.B == 0 {
+		tmp = (ratio - 1) / c.A
+	} else {
+		tmp = (-c.A + math.Sqrt(c.A*c.A-4*c.B*(1-ratio))) / (2 * c.B)
+	}
+	if tmp >= 0 || c.C == 0 {
+		return tmp
+	}
+	// Below 0°C quadratic solution is a good starting point for Newton's method
+	derivative := func(t float64) float64 {
+		return c.A + 2*c.B*t + c.C*(4*t*t*t-300*t*t)
+	}
+	return newton(c.Ratio, derivative, ratio, tmp)
+}
+
+func (p Polynomial) Ratio(tmp float64) float64 {
+	r, power := 1.0, 1.0
+	for _, coefficient := range p {
+		power *= tmp
+		r += coefficient * power
+	}
+	return r
+}
+
+func (p Polynomial) Temperature(ratio float64) float64 {
+	if len(p) == 0 {
+		return 0
+	}
+	derivative := func(t float64) float64 {
+		d, power := 0.0, 1.0
+		for i, coefficient := range p {
+			d += float64(i+1) * coefficient * power
+			power *= t
+		}
+		return d
+	}
+	return newton(p.Ratio, derivative, ratio, (ratio-1)/p[0])
+}
+
+// newton finds temperature, for which ratio(t) == target
+func newton(ratio, derivative func(float64) float64, target, t float64) float64 {
+	for i := 0; i < newtonIterations; i++ {
+		d := derivative(t)
+		if d == 0 {
+			break
+		}
+		step := (ratio(t) - target) / d
+		t -= step
+		if math.Abs(step) < newtonEpsilon {
+			break
+		}
+	}
+	return t
+}
+
+// TablePoint is a single entry of Table
+type TablePoint struct {
+	Temperature, Ratio float64
+}
+
+// Table is a lookup table, sorted by temperature. Values between points are interpolated linearly,
+// values out of table are extrapolated from the nearest segment.
+type Table []TablePoint
+
+// NewTable builds Table from curve, with points every step from min to max
+func NewTable(c Curve, min, max, step float64) Table {
+	var t Table
+	for tmp := min; tmp <= max+step/2; tmp += step {
+		t = append(t, TablePoint{Temperature: tmp, Ratio: c.Ratio(tmp)})
+	}
+	return t
+}
+
+func (t Table) Ratio(tmp float64) float64 {
+	i := sort.Search(len(t), func(i int) bool { return t[i].Temperature >= tmp })
+	return t.interpolate(i, func(p TablePoint) (float64, float64) { return p.Temperature, p.Ratio }, tmp)
+}
+
+func (t Table) Temperature(ratio float64) float64 {
+	i := sort.Search(len(t), func(i int) bool { return t[i].Ratio >= ratio })
+	return t.interpolate(i, func(p TablePoint) (float64, float64) { return p.Ratio, p.Temperature }, ratio)
+}
+
+// interpolate finds y(x) on segment ending at i, xy returns coordinates of point
+func (t Table) interpolate(i int, xy func(TablePoint) (float64, float64), x float64) float64 {
+	if len(t) < 2 {
+		return math.NaN()
+	}
+	if i < 1 {
+		i = 1
+	} else if i >= len(t) {
+		i = len(t) - 1
+	}
+	x0, y0 := xy(t[i-1])
+	x1, y1 := xy(t[i])
+	return y0 + (y1-y0)*(x-x0)/(x1-x0)
+}
+
+// rtdToResistance converts 15-bit RTD code to resistance in Ω
+func rtdToResistance(rtd uint16, refRes RefRes) float64 {
+	return float64(rtd) / 32768 * float64(refRes)
+}
+
+// resistanceToRtd is inverse of rtdToResistance, code is limited to 15 bits
+func resistanceToRtd(r float64, refRes RefRes) uint16 {
+	code := math.Round(r / float64(refRes) * 32768)
+	if code < 0 {
+		return 0
+	}
+	if code > rtdMax {
+		return rtdMax
+	}
+	return uint16(code)
+}
+
+// resistance converts 15-bit RTD code to resistance of RTD element, with leads compensated
+func (c *config) resistance(rtd uint16) float64 {
+	return rtdToResistance(rtd, c.refRes) - c.lead()
+}
+
+// toTemperature converts 15-bit RTD code to temperature in °C
+func (c *config) toTemperature(rtd uint16) float32 {
+	return float32(c.curve.Temperature(c.resistance(rtd) / float64(c.rNominal)))
+}
+
+// toRtd is inverse of toTemperature
+func (c *config) toRtd(tmp float32) uint16 {
+	r := c.curve.Ratio(float64(tmp))*float64(c.rNominal) + c.lead()
+	return resistanceToRtd(r, c.refRes)
+}
+
+// lead returns resistance of leads, which is in series with RTD. Only 2-wire connection needs compensation.
+func (c *config) lead() float64 {
+	if c.wiring != TwoWire {
+		return 0
+	}
+	return float64(c.leadRes)
+}
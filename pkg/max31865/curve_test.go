@@ -0,0 +1,93 @@
+package max31865_test
+
+import (
+	"github.com/a-clap/iot/pkg/max31865"
+	"github.com/a-clap/iot/pkg/max31865/emulator"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+// iec60751 is PT100 resistance in Ω, from IEC 60751 reference table
+var iec60751 = []struct {
+	tmp, r float64
+}{
+	{-200, 18.52}, {-150, 39.72}, {-100, 60.26}, {-50, 80.31}, {0, 100.00}, {50, 119.40}, {100, 138.51},
+	{150, 157.33}, {200, 175.86}, {300, 212.05}, {400, 247.09}, {500, 280.98}, {600, 313.71},
+	{700, 345.28}, {800, 375.70}, {850, 390.48},
+}
+
+func TestCurve_IEC60751(t *testing.T) {
+	curves := map[string]max31865.Curve{
+		"equation": max31865.PT385,
+		"lookup":   max31865.NewTable(max31865.PT385, -200, 850, 1),
+	}
+	for name, curve := range curves {
+		t.Run(name, func(t *testing.T) {
+			for _, ref := range iec60751 {
+				// Table is rounded to 0.01Ω, which is ~0.013°C
+				require.InDelta(t, ref.r, 100*curve.Ratio(ref.tmp), 0.005, ref.tmp)
+				require.InDelta(t, ref.tmp, curve.Temperature(ref.r/100), 0.015, ref.r)
+			}
+		})
+	}
+}
+
+func TestCurve_RoundTrip(t *testing.T) {
+	curves := map[string]struct {
+		curve    max31865.Curve
+		min, max float64
+	}{
+		"PT385":  {max31865.PT385, -200, 850},
+		"PT392":  {max31865.PT392, -200, 850},
+		"Ni120":  {max31865.Ni120, -80, 260},
+		"custom": {max31865.CVD{A: 3.9083e-3, B: -5.775e-7}, -50, 500},
+		"table":  {max31865.Table{{-100, 0.5}, {0, 1}, {100, 1.5}}, -100, 100},
+	}
+	for name, c := range curves {
+		t.Run(name, func(t *testing.T) {
+			for tmp := c.min; tmp <= c.max; tmp += 10 {
+				require.InDelta(t, tmp, c.curve.Temperature(c.curve.Ratio(tmp)), 1e-6, tmp)
+			}
+		})
+	}
+
+	// Fitted points of Edison curve No. 7
+	require.InDelta(t, 200.64, 120*max31865.Ni120.Ratio(100), 0.01)
+	require.InDelta(t, 66.60, 120*max31865.Ni120.Ratio(-80), 0.01)
+	require.InDelta(t, 380.31, 120*max31865.Ni120.Ratio(260), 0.01)
+}
+
+func TestSensor_PT1000Lookup(t *testing.T) {
+	r := require.New(t)
+	// Reference resistor defaults to 4.3kΩ for PT1000
+	max, m := newFaultSensor(t, max31865.RNominal(1000), max31865.Lookup)
+
+	// 100°C: 1385.06Ω / 4300Ω * 32768 = 10555 (0x293B) shifted left
+	m.On("ReadWrite", maxInitCall).Return([]byte{0x0, 0xd1, 0x52, 0x76, 0xFF, 0xFF, 0x0, 0x0, 0x0}, nil).Once()
+	tmp, err := max.Temperature()
+	r.Nil(err)
+	r.InDelta(100, tmp, 0.1)
+}
+
+func TestSensor_LeadResistance(t *testing.T) {
+	r := require.New(t)
+	dev := emulator.New()
+	// 0°C measured on leads of 1.2Ω
+	dev.SetResistance(101.2)
+
+	s, err := max31865.New(dev, max31865.TwoWire, max31865.LeadResistance(1.2))
+	r.Nil(err)
+	tmp, err := s.Temperature()
+	r.Nil(err)
+	r.InDelta(0, tmp, 0.05)
+	res, err := s.Resistance()
+	r.Nil(err)
+	r.InDelta(100, res, 0.02)
+
+	// 3-wire connection compensates leads by itself
+	s, err = max31865.New(dev, max31865.ThreeWire, max31865.LeadResistance(1.2))
+	r.Nil(err)
+	tmp, err = s.Temperature()
+	r.Nil(err)
+	r.InDelta(3.07, tmp, 0.05)
+}
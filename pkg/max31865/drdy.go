@@ -0,0 +1,68 @@
+package max31865
+
+import (
+	"sync"
+)
+
+// EdgeSource delivers falling edges of DRDY line, e.g. gpio.FallingEdges
+type EdgeSource interface {
+	// Watch calls handler on every falling edge, until Close is called
+	Watch(handler func()) error
+	Close() error
+}
+
+// DRDY is Ready driven by falling edges of MAX31865 DRDY pin
+type DRDY struct {
+	mtx      sync.Mutex
+	src      EdgeSource
+	callback func(any) error
+	args     any
+}
+
+var _ Ready = &DRDY{}
+
+func NewDRDY(src EdgeSource) *DRDY {
+	return &DRDY{src: src}
+}
+
+// Open starts watching DRDY, callback is called with args on every falling edge
+func (d *DRDY) Open(callback func(any) error, args any) error {
+	d.mtx.Lock()
+	if d.callback != nil {
+		d.mtx.Unlock()
+		return ErrReadyOpened
+	}
+	d.callback, d.args = callback, args
+	d.mtx.Unlock()
+
+	if err := d.src.Watch(d.edge); err != nil {
+		d.mtx.Lock()
+		d.callback, d.args = nil, nil
+		d.mtx.Unlock()
+		return err
+	}
+	return nil
+}
+
+// Close stops watching DRDY, callback won't be called anymore
+func (d *DRDY) Close() {
+	// Edge source has to be closed, before callback is cleared - so edge in progress is still handled
+	_ = d.src.Close()
+
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+	d.callback, d.args = nil, nil
+}
+
+func (d *DRDY) edge() {
+	// Callback is called under lock, so after Close returns it won't be called anymore.
+	// Callbacks in this package don't block.
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+	if d.callback == nil {
+		return
+	}
+	// Nothing to do with error here, it is called from edge source goroutine.
+	// The only error of sensor's callback is ErrTooMuchTriggers, which means reading is already pending.
+	_ = d.callback(d.args)
+}
@@ -0,0 +1,130 @@
+package max31865_test
+
+import (
+	"errors"
+	"github.com/a-clap/iot/pkg/max31865"
+	"github.com/stretchr/testify/require"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeEdges is an EdgeSource, which fires edges on demand
+type fakeEdges struct {
+	mtx      sync.Mutex
+	handler  func()
+	watchErr error
+	closed   int
+}
+
+func (f *fakeEdges) Watch(handler func()) error {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	if f.watchErr != nil {
+		return f.watchErr
+	}
+	f.handler = handler
+	return nil
+}
+
+func (f *fakeEdges) Close() error {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	f.handler = nil
+	f.closed++
+	return nil
+}
+
+// fall simulates falling edge, returns false if nobody watches line
+func (f *fakeEdges) fall() bool {
+	f.mtx.Lock()
+	handler := f.handler
+	f.mtx.Unlock()
+	if handler == nil {
+		return false
+	}
+	handler()
+	return true
+}
+
+func TestDRDY_Callback(t *testing.T) {
+	r := require.New(t)
+	edges := &fakeEdges{}
+	drdy := max31865.NewDRDY(edges)
+
+	var got []any
+	callback := func(args any) error {
+		got = append(got, args)
+		return nil
+	}
+	r.Nil(drdy.Open(callback, "args"))
+	r.ErrorIs(drdy.Open(callback, nil), max31865.ErrReadyOpened)
+
+	r.True(edges.fall())
+	r.True(edges.fall())
+	r.Equal([]any{"args", "args"}, got)
+
+	drdy.Close()
+	r.Equal(1, edges.closed)
+	r.False(edges.fall())
+	r.Len(got, 2)
+
+	// Can be opened again after Close
+	r.Nil(drdy.Open(callback, "again"))
+	r.True(edges.fall())
+	r.Equal("again", got[2])
+}
+
+func TestDRDY_WatchError(t *testing.T) {
+	r := require.New(t)
+	watchErr := errors.New("line busy")
+	edges := &fakeEdges{watchErr: watchErr}
+	drdy := max31865.NewDRDY(edges)
+
+	r.ErrorIs(drdy.Open(func(any) error { return nil }, nil), watchErr)
+
+	// Failed Open doesn't leave DRDY opened
+	edges.watchErr = nil
+	r.Nil(drdy.Open(func(any) error { return nil }, nil))
+}
+
+func TestDRDY_AsyncPoll(t *testing.T) {
+	r := require.New(t)
+	edges := &fakeEdges{}
+	m := new(SensorTransferMock)
+	m.On("ReadWrite", maxInitCall).Return(maxPORState, nil).Once()
+	m.On("ReadWrite", []byte{0x80, 0xd1}).Return([]byte{0x00, 0x00}, nil).Once()
+	m.On("ReadWrite", configReadback).Return([]byte{0x00, 0xd1}, nil).Once()
+	max, err := max31865.New(m, max31865.RefRes(400.0), max31865.NewDRDY(edges))
+	r.Nil(err)
+
+	data := make(chan max31865.Readings)
+	r.Nil(max.Poll(data, -1))
+
+	expected := []float32{0.0, 70.0}
+	buffers := [][]byte{
+		{0x0, 0xd1, 0x40, 0x00, 0xFF, 0xFF, 0x0, 0x0, 0x0},
+		{0x0, 0xd1, 0x51, 0x54, 0xFF, 0xFF, 0x0, 0x0, 0x0},
+	}
+	for i, buf := range buffers {
+		m.On("ReadWrite", maxInitCall).Return(buf, nil).Once()
+		r.True(edges.fall())
+		select {
+		case reading := <-data:
+			tmp, _, err := reading.Get()
+			r.Nil(err)
+			val, _ := strconv.ParseFloat(tmp, 32)
+			r.InDelta(expected[i], val, 1)
+		case <-time.After(100 * time.Millisecond):
+			r.Fail("waiting for readings too long")
+		}
+	}
+
+	m.On("Close").Return(nil)
+	r.Nil(max.Close())
+	// Poll releases DRDY line when it is done
+	r.Equal(1, edges.closed)
+	r.False(edges.fall())
+	m.AssertExpectations(t)
+}
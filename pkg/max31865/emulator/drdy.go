@@ -0,0 +1,67 @@
+package emulator
+
+import (
+	"sync"
+	"time"
+)
+
+// Line is DRDY output of Device, it implements max31865.EdgeSource.
+// Falling edge is signalled after one-shot conversion, Convert,
+// and every conversion period in continuous mode, while line is watched.
+type Line struct {
+	dev     *Device
+	mtx     sync.Mutex
+	handler func()
+	stop    chan struct{}
+	done    chan struct{}
+}
+
+// Watch calls handler on every falling edge of DRDY, until Close is called
+func (l *Line) Watch(handler func()) error {
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+	if l.handler != nil {
+		return ErrWatching
+	}
+	l.handler = handler
+	l.stop, l.done = make(chan struct{}), make(chan struct{})
+	go l.continuous(l.stop, l.done)
+	return nil
+}
+
+// Close stops watching, handler won't be called after Close returns
+func (l *Line) Close() error {
+	l.mtx.Lock()
+	stop, done := l.stop, l.done
+	l.handler, l.stop, l.done = nil, nil, nil
+	l.mtx.Unlock()
+
+	if stop != nil {
+		close(stop)
+		<-done
+	}
+	return nil
+}
+
+// continuous signals conversions done in continuous mode
+func (l *Line) continuous(stop, done chan struct{}) {
+	defer close(done)
+	for {
+		select {
+		case <-stop:
+			return
+		case <-time.After(l.dev.period()):
+			if l.dev.tick() {
+				l.signal()
+			}
+		}
+	}
+}
+
+func (l *Line) signal() {
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+	if l.handler != nil {
+		l.handler()
+	}
+}
@@ -0,0 +1,323 @@
+// Package emulator provides MAX31865 emulated on register level.
+// Device implements max31865.Transfer, so max31865.Sensor can be used without hardware:
+// configuration register behaves like in real chip, RTD code is generated from resistance set by user,
+// faults can be injected and DRDY is signalled after every conversion.
+package emulator
+
+import (
+	"errors"
+	"github.com/a-clap/iot/pkg/max31865"
+	"math"
+	"sync"
+	"time"
+)
+
+const (
+	regConf = iota
+	regRtdMsb
+	regRtdLsb
+	regHFaultMsb
+	regHFaultLsb
+	regLFaultMsb
+	regLFaultLsb
+	regFault
+	regCount
+)
+
+// Bits of configuration register
+const (
+	confFilter50Hz   = 1 << 0
+	confClearFault   = 1 << 1
+	confFaultDetect1 = 1 << 2
+	confFaultDetect2 = 1 << 3
+	confOneShot      = 1 << 5
+	confContinuous   = 1 << 6
+	confVBias        = 1 << 7
+
+	confFaultDetect = confFaultDetect1 | confFaultDetect2
+)
+
+const (
+	writeBit = 0x80
+	rtdMax   = 0x7FFF
+	// Conversion periods in continuous mode, from datasheet
+	period50Hz = 21 * time.Millisecond
+	period60Hz = 16667 * time.Microsecond
+)
+
+var (
+	ErrClosed   = errors.New("device is closed")
+	ErrEmpty    = errors.New("empty transfer")
+	ErrWatching = errors.New("drdy is already watched")
+)
+
+// Fault is a failure of RTD circuit, which is injected into Device
+type Fault int
+
+const (
+	// NoFault means RTD is connected properly
+	NoFault Fault = iota
+	// OpenRTD means RTD element is broken, conversion saturates and fault detection reports REFIN- high
+	OpenRTD
+	// ShortRTD means RTD element is shorted, conversion returns zero
+	ShortRTD
+	// Overvoltage means inputs are out of allowed range, it is reported by conversion and fault detection
+	Overvoltage
+)
+
+func (f Fault) String() string {
+	switch f {
+	case NoFault:
+		return "none"
+	case OpenRTD:
+		return "open rtd"
+	case ShortRTD:
+		return "short rtd"
+	case Overvoltage:
+		return "overvoltage"
+	}
+	return "unknown"
+}
+
+// Device is emulated MAX31865 with RTD attached
+type Device struct {
+	mtx      sync.Mutex
+	regs     [regCount]byte
+	refRes   max31865.RefRes
+	rNominal max31865.RNominal
+	curve    max31865.Curve
+	// resistance of RTD in Ω
+	resistance float64
+	fault      Fault
+	closed     bool
+	drdy       *Line
+}
+
+var _ max31865.Transfer = &Device{}
+
+// New creates Device in power-on state, with RTD at 0°C.
+// RefRes (430Ω by default), RNominal (100Ω by default) and Curve (PT385 by default) describe circuit.
+func New(args ...any) *Device {
+	d := &Device{
+		refRes:   430.0,
+		rNominal: 100.0,
+		curve:    max31865.PT385,
+	}
+	d.parse(args...)
+	d.resistance = float64(d.rNominal)
+	// High fault threshold is 0xFFFF after power-on, the rest of registers is zeroed
+	d.regs[regHFaultMsb], d.regs[regHFaultLsb] = 0xFF, 0xFF
+	d.drdy = &Line{dev: d}
+	return d
+}
+
+func (d *Device) parse(args ...any) {
+	for _, arg := range args {
+		switch arg := arg.(type) {
+		case max31865.RefRes:
+			d.refRes = arg
+		case max31865.RNominal:
+			d.rNominal = arg
+		case max31865.Curve:
+			d.curve = arg
+		}
+	}
+}
+
+// SetResistance sets resistance of RTD in Ω, it is seen by the next conversion
+func (d *Device) SetResistance(ohms float64) {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+	d.resistance = ohms
+}
+
+// SetTemperature sets resistance of RTD, so it corresponds to temperature in °C
+func (d *Device) SetTemperature(tmp float64) {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+	d.resistance = d.curve.Ratio(tmp) * float64(d.rNominal)
+}
+
+// SetFault injects fault into RTD circuit, NoFault brings it back to normal.
+// Fault status register latches faults until they are cleared, the same way as in chip.
+func (d *Device) SetFault(f Fault) {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+	d.fault = f
+}
+
+// Registers returns copy of register file
+func (d *Device) Registers() [8]byte {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+	return d.regs
+}
+
+// Convert runs single conversion immediately and signals DRDY, regardless of configuration
+func (d *Device) Convert() {
+	d.mtx.Lock()
+	d.convert()
+	d.mtx.Unlock()
+	d.drdy.signal()
+}
+
+// DRDY returns DRDY line of Device, it can be used with max31865.NewDRDY
+func (d *Device) DRDY() *Line {
+	return d.drdy
+}
+
+// ReadWrite handles single SPI transfer: first byte is address (with MSB set for write),
+// registers are auto-incremented the same way as in chip. Returned slice has the same length as write.
+func (d *Device) ReadWrite(write []byte) ([]byte, error) {
+	if len(write) == 0 {
+		return nil, ErrEmpty
+	}
+
+	d.mtx.Lock()
+	if d.closed {
+		d.mtx.Unlock()
+		return nil, ErrClosed
+	}
+	read := make([]byte, len(write))
+	addr := int(write[0] &^ writeBit)
+	converted := false
+	if write[0]&writeBit == writeBit {
+		for i, value := range write[1:] {
+			converted = d.store((addr+i)%regCount, value) || converted
+		}
+	} else {
+		if d.continuous() {
+			// Chip converts all the time, so RTD registers always hold fresh result
+			d.convert()
+		}
+		for i := range write[1:] {
+			read[i+1] = d.regs[(addr+i)%regCount]
+		}
+	}
+	d.mtx.Unlock()
+
+	if converted {
+		d.drdy.signal()
+	}
+	return read, nil
+}
+
+// Close makes Device unusable, the same way as closing SPI device
+func (d *Device) Close() error {
+	_ = d.drdy.Close()
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+	d.closed = true
+	return nil
+}
+
+// store writes register, returns true if conversion was done. RTD and fault status registers are read-only.
+func (d *Device) store(reg int, value byte) bool {
+	switch reg {
+	case regConf:
+		return d.storeConf(value)
+	case regHFaultMsb, regHFaultLsb, regLFaultMsb, regLFaultLsb:
+		d.regs[reg] = value
+	}
+	return false
+}
+
+func (d *Device) storeConf(value byte) bool {
+	if value&confClearFault != 0 {
+		d.regs[regFault] = 0
+		d.regs[regRtdLsb] &^= 0x01
+	}
+	// Clear bit is self-clearing, one-shot bit is cleared after conversion
+	d.regs[regConf] = value &^ (confClearFault | confOneShot)
+
+	switch value & confFaultDetect {
+	case confFaultDetect1, confFaultDetect:
+		// Automatic detection and the second cycle of manual one are instant
+		d.faultDetection()
+		d.regs[regConf] &^= confFaultDetect
+		return false
+	case confFaultDetect2:
+		// The first cycle of manual detection lasts until the second one is requested
+		return false
+	}
+
+	if value&confOneShot != 0 && value&confVBias != 0 {
+		d.convert()
+		return true
+	}
+	return false
+}
+
+func (d *Device) continuous() bool {
+	return d.regs[regConf]&(confContinuous|confVBias) == confContinuous|confVBias
+}
+
+// convert updates RTD registers and latches faults found by comparison with thresholds
+func (d *Device) convert() {
+	var code uint16
+	switch d.fault {
+	case OpenRTD:
+		code = rtdMax
+	case ShortRTD:
+		code = 0
+	default:
+		ratio := d.resistance / float64(d.refRes)
+		code = uint16(math.Max(0, math.Min(rtdMax, math.Floor(ratio*32768))))
+	}
+
+	status := max31865.FaultStatus(0)
+	high := (uint16(d.regs[regHFaultMsb])<<8 | uint16(d.regs[regHFaultLsb])) >> 1
+	low := (uint16(d.regs[regLFaultMsb])<<8 | uint16(d.regs[regLFaultLsb])) >> 1
+	if code >= high {
+		status |= max31865.FaultRTDHigh
+	}
+	if code <= low {
+		status |= max31865.FaultRTDLow
+	}
+	if d.fault == Overvoltage {
+		status |= max31865.FaultVoltage
+	}
+	d.latch(status)
+
+	lsb := byte(code<<1) | d.regs[regRtdLsb]&0x01
+	d.regs[regRtdMsb], d.regs[regRtdLsb] = byte(code>>7), lsb
+}
+
+// faultDetection checks voltages on inputs, only injected faults are found
+func (d *Device) faultDetection() {
+	switch d.fault {
+	case OpenRTD:
+		d.latch(max31865.FaultRefInHigh)
+	case Overvoltage:
+		d.latch(max31865.FaultVoltage)
+	}
+}
+
+// latch sets fault status bits, fault bit in RTD LSB follows them
+func (d *Device) latch(status max31865.FaultStatus) {
+	d.regs[regFault] |= byte(status)
+	if d.regs[regFault] != 0 {
+		d.regs[regRtdLsb] |= 0x01
+	}
+}
+
+// period returns time between conversions in continuous mode
+func (d *Device) period() time.Duration {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+	if d.regs[regConf]&confFilter50Hz != 0 {
+		return period50Hz
+	}
+	return period60Hz
+}
+
+// tick runs conversion, if device is in continuous mode
+func (d *Device) tick() bool {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+	if d.closed || !d.continuous() {
+		return false
+	}
+	d.convert()
+	return true
+}
@@ -0,0 +1,217 @@
+package emulator_test
+
+import (
+	"github.com/a-clap/iot/pkg/max31865"
+	"github.com/a-clap/iot/pkg/max31865/emulator"
+	"github.com/stretchr/testify/require"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestDevice_Temperature(t *testing.T) {
+	r := require.New(t)
+	dev := emulator.New()
+	s, err := max31865.New(dev, max31865.RefRes(430.0))
+	r.Nil(err)
+
+	for _, tmp := range []float64{-200, -50, 0, 21.5, 100, 450, 850} {
+		dev.SetTemperature(tmp)
+		got, err := s.Temperature()
+		r.Nil(err)
+		r.InDelta(tmp, got, 0.05)
+	}
+
+	// 138.51Ω is 100°C for PT100
+	dev.SetResistance(138.51)
+	got, err := s.Temperature()
+	r.Nil(err)
+	r.InDelta(100, got, 0.05)
+}
+
+func TestDevice_Config(t *testing.T) {
+	r := require.New(t)
+	dev := emulator.New()
+	_, err := max31865.New(dev, max31865.TwoWire, max31865.Filter60Hz)
+	r.Nil(err)
+	// VBIAS, continuous mode, 2-wire, 60Hz filter
+	r.EqualValues(0xC0, dev.Registers()[0])
+
+	dev = emulator.New()
+	_, err = max31865.New(dev, max31865.OneShot)
+	r.Nil(err)
+	// One-shot mode keeps VBIAS off while idle
+	r.EqualValues(0x11, dev.Registers()[0])
+}
+
+func TestDevice_Faults(t *testing.T) {
+	tests := []struct {
+		name      string
+		fault     emulator.Fault
+		detection max31865.FaultStatus
+		reading   max31865.FaultStatus
+	}{
+		{
+			name:      "open rtd",
+			fault:     emulator.OpenRTD,
+			detection: max31865.FaultRefInHigh,
+			reading:   max31865.FaultRTDHigh,
+		},
+		{
+			name:      "short rtd",
+			fault:     emulator.ShortRTD,
+			detection: 0,
+			reading:   max31865.FaultRTDLow,
+		},
+		{
+			name:      "overvoltage",
+			fault:     emulator.Overvoltage,
+			detection: max31865.FaultVoltage,
+			reading:   max31865.FaultVoltage,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := require.New(t)
+			dev := emulator.New()
+			s, err := max31865.New(dev, max31865.ManualDelay(time.Microsecond))
+			r.Nil(err)
+
+			dev.SetFault(tt.fault)
+			for _, mode := range []max31865.DetectionMode{max31865.AutomaticDetection, max31865.ManualDetection} {
+				status, err := s.RunFaultDetection(mode)
+				r.Nil(err)
+				r.Equal(tt.detection, status)
+				// Detection clears faults afterwards
+				r.Zero(dev.Registers()[7])
+			}
+
+			_, err = s.Temperature()
+			var fault *max31865.Fault
+			r.ErrorAs(err, &fault)
+			r.True(fault.Has(tt.reading))
+			// Sensor clears faults after failed reading
+			r.Zero(dev.Registers()[7])
+
+			dev.SetFault(emulator.NoFault)
+			_, err = s.Temperature()
+			r.Nil(err)
+		})
+	}
+}
+
+func TestDevice_Thresholds(t *testing.T) {
+	r := require.New(t)
+	dev := emulator.New()
+	s, err := max31865.New(dev, max31865.LowThreshold(-20), max31865.HighThreshold(100))
+	r.Nil(err)
+
+	low, high, err := s.Thresholds()
+	r.Nil(err)
+	r.InDelta(-20, low, 0.1)
+	r.InDelta(100, high, 0.1)
+
+	dev.SetTemperature(50)
+	_, err = s.Temperature()
+	r.Nil(err)
+
+	dev.SetTemperature(120)
+	_, err = s.Temperature()
+	var fault *max31865.Fault
+	r.ErrorAs(err, &fault)
+	r.Equal(max31865.FaultRTDHigh, fault.Status)
+
+	dev.SetTemperature(-30)
+	_, err = s.Temperature()
+	r.ErrorAs(err, &fault)
+	r.Equal(max31865.FaultRTDLow, fault.Status)
+}
+
+func TestDevice_OneShotDRDY(t *testing.T) {
+	r := require.New(t)
+	dev := emulator.New()
+	dev.SetTemperature(36.6)
+	s, err := max31865.New(dev, max31865.OneShot, max31865.ManualDelay(time.Microsecond), max31865.NewDRDY(dev.DRDY()))
+	r.Nil(err)
+
+	start := time.Now()
+	tmp, err := s.Temperature()
+	r.Nil(err)
+	r.InDelta(36.6, tmp, 0.05)
+	// DRDY ends waiting for conversion long before conversion time
+	r.Less(time.Since(start), 30*time.Millisecond)
+	// Back to idle
+	r.EqualValues(0x11, dev.Registers()[0])
+}
+
+func TestDevice_ContinuousDRDY(t *testing.T) {
+	r := require.New(t)
+	dev := emulator.New()
+	dev.SetTemperature(25)
+	s, err := max31865.New(dev, max31865.NewDRDY(dev.DRDY()))
+	r.Nil(err)
+
+	data := make(chan max31865.Readings)
+	r.Nil(s.Poll(data, -1))
+	for i := 0; i < 3; i++ {
+		select {
+		case reading := <-data:
+			tmp, _, err := reading.Get()
+			r.Nil(err)
+			val, _ := strconv.ParseFloat(tmp, 32)
+			r.InDelta(25, val, 0.05)
+		case <-time.After(100 * time.Millisecond):
+			r.Fail("DRDY not signalled")
+		}
+	}
+	r.Nil(s.Close())
+
+	_, err = dev.ReadWrite([]byte{0x00, 0x00})
+	r.ErrorIs(err, emulator.ErrClosed)
+}
+
+func TestDevice_ReadWrite(t *testing.T) {
+	r := require.New(t)
+	dev := emulator.New()
+
+	// Power-on state
+	read, err := dev.ReadWrite(make([]byte, 9))
+	r.Nil(err)
+	r.Equal([]byte{0x00, 0x00, 0x00, 0x00, 0xFF, 0xFF, 0x00, 0x00, 0x00}, read)
+
+	// RTD and fault status registers are read-only, address wraps around
+	_, err = dev.ReadWrite([]byte{0x81, 0x12, 0x34, 0xFF, 0xFE, 0x00, 0x02, 0xDE, 0x01})
+	r.Nil(err)
+	r.Equal([8]byte{0x01, 0x00, 0x00, 0xFF, 0xFE, 0x00, 0x02, 0x00}, dev.Registers())
+
+	// Conversion is done only on one-shot with VBIAS on
+	dev.SetResistance(215)
+	_, err = dev.ReadWrite([]byte{0x80, 0x20})
+	r.Nil(err)
+	r.Zero(dev.Registers()[1])
+	_, err = dev.ReadWrite([]byte{0x80, 0xA0})
+	r.Nil(err)
+	regs := dev.Registers()
+	// Half of reference resistance, one-shot bit is cleared
+	r.Equal([]byte{0x80, 0x80, 0x00}, regs[:3])
+	r.Zero(regs[7])
+
+	// Code above high threshold latches fault, fault bit in RTD LSB follows it
+	_, err = dev.ReadWrite([]byte{0x83, 0x70, 0x00})
+	r.Nil(err)
+	_, err = dev.ReadWrite([]byte{0x80, 0xA0})
+	r.Nil(err)
+	regs = dev.Registers()
+	r.EqualValues(0x01, regs[2])
+	r.EqualValues(max31865.FaultRTDHigh, regs[7])
+
+	// Clear bit resets faults and doesn't stay in config register
+	_, err = dev.ReadWrite([]byte{0x80, 0x82})
+	r.Nil(err)
+	regs = dev.Registers()
+	r.Equal([]byte{0x80, 0x80, 0x00}, regs[:3])
+	r.Zero(regs[7])
+
+	_, err = dev.ReadWrite(nil)
+	r.ErrorIs(err, emulator.ErrEmpty)
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"fmt"
+	"github.com/a-clap/iot/pkg/max31865"
+	"github.com/a-clap/iot/pkg/max31865/emulator"
+	"log"
+	"time"
+)
+
+func main() {
+	dev := emulator.New(max31865.RefRes(430.0), max31865.RNominal(100.0))
+	dev.SetTemperature(20)
+
+	sensor, err := max31865.New(dev, max31865.ID("emulated"), max31865.RefRes(430.0), max31865.NewDRDY(dev.DRDY()))
+	if err != nil {
+		log.Fatalln(err)
+	}
+
+	data := make(chan max31865.Readings, 10)
+	if err := sensor.Poll(data, -1); err != nil {
+		log.Fatalln(err)
+	}
+
+	// Heat up slowly, then break RTD for a while
+	go func() {
+		for tmp := 20.0; tmp < 30.0; tmp += 0.5 {
+			dev.SetTemperature(tmp)
+			<-time.After(100 * time.Millisecond)
+		}
+		dev.SetFault(emulator.OpenRTD)
+		<-time.After(200 * time.Millisecond)
+		dev.SetFault(emulator.NoFault)
+	}()
+
+	timeout := time.After(3 * time.Second)
+	for {
+		select {
+		case r := <-data:
+			tmp, stamp, err := r.Get()
+			fmt.Printf("id: %s, Temperature: %s. Time: %s, err: %v \n", r.ID(), tmp, stamp, err)
+		case <-timeout:
+			if err := sensor.Close(); err != nil {
+				log.Fatalln(err)
+			}
+			fmt.Println("finished")
+			return
+		}
+	}
+}
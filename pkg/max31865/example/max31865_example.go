@@ -12,6 +12,15 @@ func main() {
 	if err != nil {
 		panic(err)
 	}
+	// Check wiring, before trusting readings
+	status, err := dev.RunFaultDetection(max31865.AutomaticDetection)
+	if err != nil {
+		panic(err)
+	}
+	if !status.OK() {
+		log.Fatalln(status.Causes(max31865.ThreeWire))
+	}
+
 	for i := 0; i < 5; i++ {
 
 		t, err := dev.Temperature()
@@ -0,0 +1,181 @@
+package max31865
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// DetectionMode selects how fault detection cycle is timed
+type DetectionMode int
+
+const (
+	// AutomaticDetection lets MAX31865 time the whole cycle, it takes ~550µs
+	AutomaticDetection DetectionMode = iota
+	// ManualDetection is timed by driver with ManualDelay, needed when input filter time constant is long
+	ManualDetection
+)
+
+// ManualDelay is a time to wait between steps of manual fault detection, it should be at least 5 time constants of input filter
+type ManualDelay time.Duration
+
+// FaultStatus is a content of fault status register
+type FaultStatus uint8
+
+const (
+	FaultVoltage   FaultStatus = 1 << (iota + 2) // Overvoltage or undervoltage
+	FaultRTDInLow                                // RTDIN- < 0.85 x VBIAS (FORCE- open)
+	FaultRefInLow                                // REFIN- < 0.85 x VBIAS (FORCE- open)
+	FaultRefInHigh                               // REFIN- > 0.85 x VBIAS
+	FaultRTDLow                                  // RTD low threshold
+	FaultRTDHigh                                 // RTD high threshold
+)
+
+const (
+	// faultDetectPolls is the maximum number of config register reads, while waiting for fault detection
+	faultDetectPolls = 100
+	// faultDetectInterval is a time between config register reads
+	faultDetectInterval = 100 * time.Microsecond
+	// defaultManualDelay is 5 time constants of filter recommended by datasheet (1kΩ, 100nF)
+	defaultManualDelay = ManualDelay(500 * time.Microsecond)
+)
+
+// faultNames are short names of fault bits D2..D7
+var faultNames = [...]string{"overvoltage", "RTDIN- low", "REFIN- low", "REFIN- high", "RTD low threshold", "RTD high threshold"}
+
+// OK is true, when no fault was detected
+func (f FaultStatus) OK() bool {
+	return f == 0
+}
+
+// Has checks whether every bit of flag is set
+func (f FaultStatus) Has(flag FaultStatus) bool {
+	return flag != 0 && f&flag == flag
+}
+
+// Flags splits status into single fault bits
+func (f FaultStatus) Flags() []FaultStatus {
+	var flags []FaultStatus
+	for flag := FaultVoltage; flag != 0 && flag <= FaultRTDHigh; flag <<= 1 {
+		if f.Has(flag) {
+			flags = append(flags, flag)
+		}
+	}
+	return flags
+}
+
+func (f FaultStatus) String() string {
+	if f.OK() {
+		return "no fault"
+	}
+	var names []string
+	for _, flag := range f.Flags() {
+		for i := range faultNames {
+			if flag == FaultVoltage<<i {
+				names = append(names, faultNames[i])
+			}
+		}
+	}
+	return strings.Join(names, ", ")
+}
+
+// Causes returns possible causes of faults, which depend on wiring
+func (f FaultStatus) Causes(w Wiring) []string {
+	return errorCauses(byte(f), w)
+}
+
+// Err returns Fault, if any fault bit is set
+func (f FaultStatus) Err(w Wiring) error {
+	if f.OK() {
+		return nil
+	}
+	return &Fault{Status: f, Wiring: w}
+}
+
+// Fault is an error reported by sensor in fault status register, it wraps ErrRtd
+type Fault struct {
+	Status FaultStatus
+	Wiring Wiring
+}
+
+func (f *Fault) Error() string {
+	return fmt.Sprintf("%v: %v (%#02x), possible causes: %v", ErrRtd, f.Status, uint8(f.Status), strings.Join(f.Causes(), "; "))
+}
+
+func (f *Fault) Unwrap() error {
+	return ErrRtd
+}
+
+// Has checks whether fault flag is set
+func (f *Fault) Has(flag FaultStatus) bool {
+	return f.Status.Has(flag)
+}
+
+// Causes returns human readable causes of fault, specific for wiring
+func (f *Fault) Causes() []string {
+	return f.Status.Causes(f.Wiring)
+}
+
+// RunFaultDetection runs fault-detection cycle and returns content of fault status register.
+// Faults are cleared afterwards and sensor goes back to its configuration.
+// It should be run before Poll, e.g. to check wiring at startup.
+func (s *sensor) RunFaultDetection(mode DetectionMode) (FaultStatus, error) {
+	if s.cfg.polling.Load() {
+		return 0, ErrAlreadyPolling
+	}
+	return s.detectFaults(mode)
+}
+
+func (s *sensor) detectFaults(mode DetectionMode) (FaultStatus, error) {
+	if err := s.startFaultDetection(mode); err != nil {
+		return 0, err
+	}
+	if err := s.waitFaultDetection(); err != nil {
+		return 0, err
+	}
+
+	r, err := s.read(regFault, 1)
+	if err != nil {
+		return 0, err
+	}
+	// Restore config and clear faults
+	if err := s.clearFaults(); err != nil {
+		return 0, err
+	}
+	return FaultStatus(r[0]), nil
+}
+
+func (s *sensor) startFaultDetection(mode DetectionMode) error {
+	switch mode {
+	case AutomaticDetection:
+		return s.write(regConf, []byte{s.regCfg.faultDetect()})
+	case ManualDetection:
+		delay := time.Duration(s.cfg.manualDelay)
+		// VBIAS has to be on for at least 5 time constants before first cycle
+		if err := s.write(regConf, []byte{s.regCfg.faultDetectIdle()}); err != nil {
+			return err
+		}
+		<-time.After(delay)
+		if err := s.write(regConf, []byte{s.regCfg.faultDetectManual(1)}); err != nil {
+			return err
+		}
+		<-time.After(delay)
+		return s.write(regConf, []byte{s.regCfg.faultDetectManual(2)})
+	}
+	return ErrDetectionMode
+}
+
+// waitFaultDetection polls config register until fault detection is finished
+func (s *sensor) waitFaultDetection() error {
+	for i := 0; i < faultDetectPolls; i++ {
+		r, err := s.read(regConf, 1)
+		if err != nil {
+			return err
+		}
+		if s.regCfg.faultDetectFinished(r[0]) {
+			return nil
+		}
+		<-time.After(faultDetectInterval)
+	}
+	return ErrFaultDetectionTimeout
+}
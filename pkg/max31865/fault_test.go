@@ -0,0 +1,121 @@
+package max31865_test
+
+import (
+	"github.com/a-clap/iot/pkg/max31865"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func newFaultSensor(t *testing.T, args ...any) (max31865.Sensor, *SensorTransferMock) {
+	m := new(SensorTransferMock)
+	m.On("ReadWrite", maxInitCall).Return(maxPORState, nil).Once()
+	m.On("ReadWrite", []byte{0x80, 0xd1}).Return([]byte{0x00, 0x00}, nil).Once()
+	m.On("ReadWrite", configReadback).Return([]byte{0x00, 0xd1}, nil).Once()
+	max, err := max31865.New(m, args...)
+	require.Nil(t, err)
+	return max, m
+}
+
+func TestSensor_RunFaultDetection(t *testing.T) {
+	tests := []struct {
+		name   string
+		mode   max31865.DetectionMode
+		writes [][]byte
+		fault  byte
+		causes int
+	}{
+		{
+			name:   "automatic, no fault",
+			mode:   max31865.AutomaticDetection,
+			writes: [][]byte{{0x80, 0x95}},
+			fault:  0x00,
+		},
+		{
+			name:   "automatic, open rtd",
+			mode:   max31865.AutomaticDetection,
+			writes: [][]byte{{0x80, 0x95}},
+			fault:  0x84,
+			causes: 2,
+		},
+		{
+			name:   "manual",
+			mode:   max31865.ManualDetection,
+			writes: [][]byte{{0x80, 0x91}, {0x80, 0x99}, {0x80, 0x9d}},
+			fault:  0x08,
+			causes: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := require.New(t)
+			max, m := newFaultSensor(t, max31865.ManualDelay(time.Microsecond))
+
+			for _, w := range tt.writes {
+				m.On("ReadWrite", w).Return(make([]byte, len(w)), nil).Once()
+			}
+			// Still running on first read
+			m.On("ReadWrite", []byte{0x00, 0x00}).Return([]byte{0x00, 0x9d}, nil).Once()
+			m.On("ReadWrite", []byte{0x00, 0x00}).Return([]byte{0x00, 0x91}, nil).Once()
+			m.On("ReadWrite", []byte{0x07, 0x00}).Return([]byte{0x00, tt.fault}, nil).Once()
+			// Faults are cleared and config restored
+			m.On("ReadWrite", []byte{0x80, 0xd3}).Return([]byte{0x00, 0x00}, nil).Once()
+
+			status, err := max.RunFaultDetection(tt.mode)
+			r.Nil(err)
+			r.Equal(max31865.FaultStatus(tt.fault), status)
+			r.Equal(tt.fault == 0, status.OK())
+			r.Len(status.Causes(max31865.ThreeWire), tt.causes)
+			m.AssertExpectations(t)
+		})
+	}
+}
+
+func TestSensor_RunFaultDetectionTimeout(t *testing.T) {
+	max, m := newFaultSensor(t)
+	m.On("ReadWrite", []byte{0x80, 0x95}).Return([]byte{0x00, 0x00}, nil).Once()
+	m.On("ReadWrite", []byte{0x00, 0x00}).Return([]byte{0x00, 0x95}, nil)
+
+	_, err := max.RunFaultDetection(max31865.AutomaticDetection)
+	require.ErrorIs(t, err, max31865.ErrFaultDetectionTimeout)
+
+	_, err = max.RunFaultDetection(max31865.DetectionMode(5))
+	require.ErrorIs(t, err, max31865.ErrDetectionMode)
+}
+
+func TestFaultStatus(t *testing.T) {
+	r := require.New(t)
+	status := max31865.FaultRTDHigh | max31865.FaultVoltage
+
+	r.False(status.OK())
+	r.True(status.Has(max31865.FaultRTDHigh))
+	r.False(status.Has(max31865.FaultRTDLow))
+	r.Equal([]max31865.FaultStatus{max31865.FaultVoltage, max31865.FaultRTDHigh}, status.Flags())
+	r.Equal("overvoltage, RTD high threshold", status.String())
+	r.Nil(max31865.FaultStatus(0).Err(max31865.ThreeWire))
+
+	err := status.Err(max31865.TwoWire)
+	var fault *max31865.Fault
+	r.ErrorAs(err, &fault)
+	r.ErrorIs(err, max31865.ErrRtd)
+	r.Equal([]string{"Overvoltage or undervoltage fault", "Open RTD element"}, fault.Causes())
+}
+
+func TestSensor_TemperatureFault(t *testing.T) {
+	r := require.New(t)
+	max, m := newFaultSensor(t)
+
+	// Fault bit in rtd lsb, RTD high threshold and REFIN- high in fault register
+	m.On("ReadWrite", maxInitCall).Return([]byte{0x0, 0xd1, 0x7F, 0xFF, 0xFF, 0xFF, 0x0, 0x0, 0xA0}, nil).Once()
+	m.On("ReadWrite", []byte{0x80, 0xd3}).Return([]byte{0x00, 0x00}, nil).Once()
+
+	_, err := max.Temperature()
+	var fault *max31865.Fault
+	r.ErrorAs(err, &fault)
+	r.Equal(max31865.ThreeWire, fault.Wiring)
+	r.True(fault.Has(max31865.FaultRTDHigh))
+	r.True(fault.Has(max31865.FaultRefInHigh))
+	r.False(fault.Has(max31865.FaultVoltage))
+	r.Len(fault.Causes(), 2)
+	m.AssertExpectations(t)
+}
@@ -3,7 +3,6 @@ package max31865
 import (
 	"errors"
 	"io"
-	"math"
 )
 
 const (
@@ -26,6 +25,13 @@ var (
 	ErrWrongArgs        = errors.New("wrong args passed to callback")
 	ErrNoReadyInterface = errors.New("lack of ready interface")
 	ErrTooMuchTriggers  = errors.New("poll received too much triggers")
+
+	ErrDetectionMode         = errors.New("unknown fault detection mode")
+	ErrFaultDetectionTimeout = errors.New("fault detection didn't finish in time")
+	ErrThreshold             = errors.New("low threshold above high threshold")
+	ErrOneShotAsync          = errors.New("one-shot mode can't be polled on DRDY")
+	ErrReadyOpened           = errors.New("ready is already opened")
+	ErrConfig                = errors.New("config register mismatch")
 )
 
 type Transfer interface {
@@ -82,45 +88,3 @@ func checkTransfer(t Transfer) error {
 	}
 	return nil
 }
-
-func rtdToTemperature(rtd uint16, refResT RefRes, rNominalT RNominal) float32 {
-	refRes := float32(refResT)
-	rNominal := float32(rNominalT)
-	const (
-		RtdA float32 = 3.9083e-3
-		RtdB float32 = -5.775e-7
-	)
-	Rt := float32(rtd)
-	Rt /= 32768
-	Rt *= refRes
-
-	Z1 := -RtdA
-	Z2 := RtdA*RtdA - (4 * RtdB)
-	Z3 := (4 * RtdB) / rNominal
-	Z4 := 2 * RtdB
-
-	temp := Z2 + (Z3 * Rt)
-	temp = (float32(math.Sqrt(float64(temp))) + Z1) / Z4
-
-	if temp >= 0 {
-		return temp
-	}
-
-	Rt /= rNominal
-	Rt *= 100
-
-	rpoly := Rt
-
-	temp = -242.02
-	temp += 2.2228 * rpoly
-	rpoly *= Rt
-	temp += 2.5859e-3 * rpoly
-	rpoly *= rNominal
-	temp -= 4.8260e-6 * rpoly
-	rpoly *= Rt
-	temp -= 2.8183e-8 * rpoly
-	rpoly *= Rt
-	temp += 1.5243e-10 * rpoly
-
-	return temp
-}
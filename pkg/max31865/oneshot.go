@@ -0,0 +1,71 @@
+package max31865
+
+import (
+	"time"
+)
+
+// Maximum one-shot conversion times from datasheet
+const (
+	conversionTime50Hz = 66 * time.Millisecond
+	conversionTime60Hz = 55 * time.Millisecond
+)
+
+// oneShot turns VBIAS on, runs single conversion and returns all registers, VBIAS is turned off afterwards
+func (s *sensor) oneShot() ([]byte, error) {
+	if err := s.write(regConf, []byte{s.regCfg.biasOn()}); err != nil {
+		return nil, err
+	}
+	// VBIAS needs 10.5 time constants of input filter (ManualDelay is 5 of them) plus 1ms
+	<-time.After(time.Duration(s.cfg.manualDelay)*21/10 + time.Millisecond)
+
+	wait := s.conversionWaiter()
+	if err := s.write(regConf, []byte{s.regCfg.oneShot()}); err != nil {
+		return nil, err
+	}
+	wait()
+
+	r, err := s.read(regConf, regFault+1)
+	if err != nil {
+		return nil, err
+	}
+	// Back to idle, without VBIAS
+	if err := s.write(regConf, []byte{s.regCfg.reg()}); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// conversionWaiter returns function, which blocks until conversion is done.
+// It relies on DRDY, if Ready is available and not used by polling. Otherwise, it waits conversion time.
+func (s *sensor) conversionWaiter() func() {
+	timeout := conversionTime50Hz
+	if s.cfg.filter == Filter60Hz {
+		timeout = conversionTime60Hz
+	}
+	sleep := func() {
+		<-time.After(timeout)
+	}
+	if s.cfg.ready == nil || s.cfg.polling.Load() {
+		return sleep
+	}
+
+	done := make(chan struct{}, 1)
+	notify := func(any) error {
+		select {
+		case done <- struct{}{}:
+		default:
+		}
+		return nil
+	}
+	if err := s.cfg.ready.Open(notify, nil); err != nil {
+		return sleep
+	}
+	return func() {
+		defer s.cfg.ready.Close()
+		// DRDY may be missed, conversion is done after timeout anyway
+		select {
+		case <-done:
+		case <-time.After(timeout):
+		}
+	}
+}
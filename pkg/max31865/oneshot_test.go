@@ -0,0 +1,92 @@
+package max31865_test
+
+import (
+	"github.com/a-clap/iot/pkg/max31865"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+// drdyReady signals DRDY as soon as it is opened
+type drdyReady struct {
+	opened, closed int
+}
+
+func (d *drdyReady) Open(callback func(any) error, args any) error {
+	d.opened++
+	return callback(args)
+}
+
+func (d *drdyReady) Close() {
+	d.closed++
+}
+
+func TestSensor_OneShot(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []any
+		idle    byte
+		ready   *drdyReady
+		minTime time.Duration
+		maxTime time.Duration
+	}{
+		{
+			name:    "50Hz waits conversion time",
+			args:    []any{max31865.OneShot, max31865.Filter50Hz},
+			idle:    0x11,
+			minTime: 66 * time.Millisecond,
+			maxTime: 100 * time.Millisecond,
+		},
+		{
+			name:    "60Hz waits conversion time",
+			args:    []any{max31865.OneShot, max31865.Filter60Hz},
+			idle:    0x10,
+			minTime: 55 * time.Millisecond,
+			maxTime: 80 * time.Millisecond,
+		},
+		{
+			name:    "DRDY ends waiting",
+			args:    []any{max31865.OneShot},
+			idle:    0x11,
+			ready:   &drdyReady{},
+			minTime: 0,
+			maxTime: 20 * time.Millisecond,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := require.New(t)
+			args := append(tt.args, max31865.RefRes(400.0), max31865.ManualDelay(time.Microsecond))
+			if tt.ready != nil {
+				args = append(args, tt.ready)
+			}
+			m := new(SensorTransferMock)
+			m.On("ReadWrite", maxInitCall).Return(maxPORState, nil).Once()
+			m.On("ReadWrite", []byte{0x80, tt.idle}).Return([]byte{0x00, 0x00}, nil).Once()
+			m.On("ReadWrite", configReadback).Return([]byte{0x00, tt.idle}, nil).Once()
+			max, err := max31865.New(m, args...)
+			r.Nil(err)
+
+			// VBIAS on, then single conversion, then back to idle
+			m.On("ReadWrite", []byte{0x80, tt.idle | 0x80}).Return([]byte{0x00, 0x00}, nil).Once()
+			m.On("ReadWrite", []byte{0x80, tt.idle | 0xA0}).Return([]byte{0x00, 0x00}, nil).Once()
+			m.On("ReadWrite", maxInitCall).Return([]byte{0x0, tt.idle, 0x40, 0x00, 0xFF, 0xFF, 0x0, 0x0, 0x0}, nil).Once()
+			m.On("ReadWrite", []byte{0x80, tt.idle}).Return([]byte{0x00, 0x00}, nil).Once()
+
+			start := time.Now()
+			tmp, err := max.Temperature()
+			elapsed := time.Since(start)
+			r.Nil(err)
+			r.InDelta(0, tmp, 0.1)
+			r.GreaterOrEqual(elapsed, tt.minTime)
+			r.Less(elapsed, tt.maxTime)
+			m.AssertExpectations(t)
+			if tt.ready != nil {
+				r.Equal(1, tt.ready.opened)
+				r.Equal(1, tt.ready.closed)
+				// Nothing would start conversion
+				r.ErrorIs(max.Poll(make(chan max31865.Readings), -1), max31865.ErrOneShotAsync)
+			}
+		})
+	}
+}
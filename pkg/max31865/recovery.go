@@ -0,0 +1,143 @@
+package max31865
+
+import (
+	"time"
+)
+
+// State is a health of sensor during Poll
+type State int
+
+const (
+	// Healthy means the last reading succeeded
+	Healthy State = iota
+	// Degraded means reading failed and sensor is being recovered
+	Degraded
+	// Failed means recovery didn't succeed in Recovery.Retries attempts.
+	// Poll still reads sensor, first successful reading makes it Healthy again.
+	Failed
+)
+
+func (s State) String() string {
+	switch s {
+	case Healthy:
+		return "healthy"
+	case Degraded:
+		return "degraded"
+	case Failed:
+		return "failed"
+	}
+	return "unknown"
+}
+
+// Recovery is a policy of recovering sensor from faults during Poll.
+// Each attempt checks interface, rewrites configuration, optionally runs fault detection and verifies reading.
+type Recovery struct {
+	// Retries is a number of attempts, before sensor is considered Failed. Zero disables recovery.
+	Retries int
+	// Backoff is a delay before the first attempt, it is doubled after each failed one, up to MaxBackoff
+	Backoff    time.Duration
+	MaxBackoff time.Duration
+	// Detection runs fault detection cycle in DetectionMode during recovery
+	Detection     bool
+	DetectionMode DetectionMode
+}
+
+// StateEvent is sent on StateEvents, whenever State of polled sensor changes
+type StateEvent struct {
+	ID        string
+	From, To  State
+	Err       error
+	Timestamp time.Time
+}
+
+// StateEvents receives StateEvent during Poll. It has to be read the same way as Readings, otherwise Poll blocks.
+// Sensor is the sender, so it closes StateEvents together with Readings channel, when Poll stops.
+type StateEvents chan StateEvent
+
+// DefaultRecovery retries three times, with backoff from 100ms to 1s
+var DefaultRecovery = Recovery{
+	Retries:    3,
+	Backoff:    100 * time.Millisecond,
+	MaxBackoff: time.Second,
+}
+
+// setState updates state and emits StateEvent, if it changed. Returns false if Poll was stopped meanwhile.
+func (s *sensor) setState(state State, err error) bool {
+	if s.state == state {
+		return true
+	}
+	e := StateEvent{ID: s.ID(), From: s.state, To: state, Err: err, Timestamp: time.Now()}
+	s.state = state
+	if s.cfg.events == nil {
+		return true
+	}
+	select {
+	case s.cfg.events <- e:
+		return true
+	case <-s.stop:
+		s.cfg.polling.Store(false)
+		return false
+	}
+}
+
+// tryRecover tries to bring sensor back after failed reading, according to Recovery policy.
+// Failed sensor isn't recovered anymore, only successful reading brings it back.
+func (s *sensor) tryRecover(err error) {
+	if s.state == Failed {
+		return
+	}
+	if !s.setState(Degraded, err) {
+		return
+	}
+	policy := s.cfg.recovery
+	if policy.Retries <= 0 {
+		return
+	}
+	backoff := policy.Backoff
+	for attempt := 0; attempt < policy.Retries; attempt++ {
+		if !s.wait(backoff) {
+			return
+		}
+		if err = s.reinit(); err == nil {
+			s.setState(Healthy, nil)
+			return
+		}
+		backoff *= 2
+		if policy.MaxBackoff > 0 && backoff > policy.MaxBackoff {
+			backoff = policy.MaxBackoff
+		}
+	}
+	s.setState(Failed, err)
+}
+
+// wait sleeps for duration, returns false if Poll was stopped meanwhile
+func (s *sensor) wait(d time.Duration) bool {
+	select {
+	case <-time.After(d):
+		return true
+	case <-s.stop:
+		s.cfg.polling.Store(false)
+		return false
+	}
+}
+
+// reinit is a single recovery attempt
+func (s *sensor) reinit() error {
+	if err := checkTransfer(s.Transfer); err != nil {
+		return err
+	}
+	if err := s.config(); err != nil {
+		return err
+	}
+	if s.cfg.recovery.Detection {
+		status, err := s.detectFaults(s.cfg.recovery.DetectionMode)
+		if err != nil {
+			return err
+		}
+		if !status.OK() {
+			return &Fault{Status: status, Wiring: s.cfg.wiring}
+		}
+	}
+	_, err := s.RTDCode()
+	return err
+}
@@ -0,0 +1,160 @@
+package max31865_test
+
+import (
+	"github.com/a-clap/iot/pkg/max31865"
+	"github.com/a-clap/iot/pkg/max31865/emulator"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+// recoverySensor creates polled sensor on emulator with recovery policy
+func recoverySensor(t *testing.T, policy max31865.Recovery) (*emulator.Device, max31865.Sensor, chan max31865.Readings, max31865.StateEvents) {
+	dev := emulator.New()
+	events := make(max31865.StateEvents)
+	s, err := max31865.New(dev, max31865.RefRes(430.0), policy, events)
+	require.Nil(t, err)
+	data := make(chan max31865.Readings)
+	require.Nil(t, s.Poll(data, 5*time.Millisecond))
+	return dev, s, data, events
+}
+
+func nextEvent(t *testing.T, data chan max31865.Readings, events max31865.StateEvents) max31865.StateEvent {
+	deadline := time.After(time.Second)
+	for {
+		select {
+		case <-data:
+		case e := <-events:
+			return e
+		case <-deadline:
+			require.Fail(t, "waiting for state event too long")
+		}
+	}
+}
+
+func nextReading(t *testing.T, data chan max31865.Readings) max31865.Readings {
+	select {
+	case r := <-data:
+		return r
+	case <-time.After(time.Second):
+		require.Fail(t, "waiting for readings too long")
+	}
+	return nil
+}
+
+func TestSensor_RecoveryTransient(t *testing.T) {
+	r := require.New(t)
+	dev, s, data, events := recoverySensor(t, max31865.Recovery{Retries: 3, Backoff: time.Millisecond})
+
+	reading := nextReading(t, data)
+	_, _, err := reading.Get()
+	r.Nil(err)
+	r.Equal(max31865.Healthy, reading.State())
+
+	dev.SetFault(emulator.OpenRTD)
+	e := nextEvent(t, data, events)
+	r.Equal(max31865.Healthy, e.From)
+	r.Equal(max31865.Degraded, e.To)
+	r.ErrorIs(e.Err, max31865.ErrRtd)
+
+	// Fault is gone before the first attempt
+	dev.SetFault(emulator.NoFault)
+	e = nextEvent(t, data, events)
+	r.Equal(max31865.Degraded, e.From)
+	r.Equal(max31865.Healthy, e.To)
+	r.Nil(e.Err)
+
+	// Reading, which caused recovery, still carries error
+	reading = nextReading(t, data)
+	_, _, err = reading.Get()
+	r.ErrorIs(err, max31865.ErrRtd)
+	r.Equal(max31865.Healthy, reading.State())
+
+	reading = nextReading(t, data)
+	_, _, err = reading.Get()
+	r.Nil(err)
+	r.Nil(s.Close())
+}
+
+func TestSensor_RecoveryFailed(t *testing.T) {
+	r := require.New(t)
+	policy := max31865.Recovery{
+		Retries:       3,
+		Backoff:       10 * time.Millisecond,
+		MaxBackoff:    15 * time.Millisecond,
+		Detection:     true,
+		DetectionMode: max31865.AutomaticDetection,
+	}
+	dev, s, data, events := recoverySensor(t, policy)
+
+	dev.SetFault(emulator.OpenRTD)
+	degraded := nextEvent(t, data, events)
+	r.Equal(max31865.Degraded, degraded.To)
+
+	failed := nextEvent(t, data, events)
+	r.Equal(max31865.Degraded, failed.From)
+	r.Equal(max31865.Failed, failed.To)
+	// Backoff is doubled, up to MaxBackoff: 10ms + 15ms + 15ms
+	r.GreaterOrEqual(failed.Timestamp.Sub(degraded.Timestamp), 40*time.Millisecond)
+	// Fault detection found open RTD
+	var fault *max31865.Fault
+	r.ErrorAs(failed.Err, &fault)
+	r.True(fault.Has(max31865.FaultRefInHigh))
+
+	// Failed sensor is still read, but not recovered anymore
+	for i := 0; i < 3; i++ {
+		reading := nextReading(t, data)
+		_, _, err := reading.Get()
+		r.ErrorIs(err, max31865.ErrRtd)
+		r.Equal(max31865.Failed, reading.State())
+	}
+
+	dev.SetFault(emulator.NoFault)
+	e := nextEvent(t, data, events)
+	r.Equal(max31865.Failed, e.From)
+	r.Equal(max31865.Healthy, e.To)
+	r.Nil(s.Close())
+}
+
+func TestSensor_RecoveryDisabled(t *testing.T) {
+	r := require.New(t)
+	dev, s, data, events := recoverySensor(t, max31865.Recovery{})
+
+	dev.SetFault(emulator.ShortRTD)
+	e := nextEvent(t, data, events)
+	r.Equal(max31865.Degraded, e.To)
+	for i := 0; i < 3; i++ {
+		reading := nextReading(t, data)
+		_, _, err := reading.Get()
+		r.ErrorIs(err, max31865.ErrRtd)
+		r.Equal(max31865.Degraded, reading.State())
+	}
+
+	dev.SetFault(emulator.NoFault)
+	e = nextEvent(t, data, events)
+	r.Equal(max31865.Healthy, e.To)
+	r.Nil(s.Close())
+}
+
+func TestSensor_RecoveryStoppedByClose(t *testing.T) {
+	r := require.New(t)
+	dev, s, data, events := recoverySensor(t, max31865.Recovery{Retries: 1, Backoff: time.Hour})
+
+	dev.SetFault(emulator.OpenRTD)
+	e := nextEvent(t, data, events)
+	r.Equal(max31865.Degraded, e.To)
+
+	done := make(chan struct{})
+	go func() {
+		r.Nil(s.Close())
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		r.Fail("Close should interrupt backoff")
+	}
+	// Consumer reading only events learns that polling ended
+	_, ok := <-events
+	r.False(ok)
+}
@@ -0,0 +1,114 @@
+package max31865
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ConfigRegister is a content of configuration register
+type ConfigRegister uint8
+
+// ConfigError is returned, when configuration register read back differs from written one
+type ConfigError struct {
+	Written, Read ConfigRegister
+}
+
+func (c *ConfigError) Error() string {
+	return fmt.Sprintf("%v: written %v, read %v", ErrConfig, c.Written, c.Read)
+}
+
+func (c *ConfigError) Unwrap() error {
+	return ErrConfig
+}
+
+// Registers is a dump of all MAX31865 registers, for diagnostics and commissioning
+type Registers struct {
+	Config ConfigRegister
+	// RTD is 15-bit code, RTDFault is its D0 bit, set when any fault was detected
+	RTD      uint16
+	RTDFault bool
+	// HighThreshold and LowThreshold are 15-bit codes, compared with RTD
+	HighThreshold uint16
+	LowThreshold  uint16
+	FaultStatus   FaultStatus
+}
+
+// Registers reads all registers at once. Contrary to Temperature, it doesn't start conversion nor clear faults.
+func (s *sensor) Registers() (Registers, error) {
+	r, err := s.read(regConf, regFault+1)
+	if err != nil {
+		return Registers{}, err
+	}
+	code := func(reg int) uint16 {
+		return (uint16(r[reg])<<8 | uint16(r[reg+1])) >> 1
+	}
+	return Registers{
+		Config:        ConfigRegister(r[regConf]),
+		RTD:           code(regRtdMsb),
+		RTDFault:      r[regRtdLsb]&0x1 == 0x1,
+		HighThreshold: code(regHFaultMsb),
+		LowThreshold:  code(regLFaultMsb),
+		FaultStatus:   FaultStatus(r[regFault]),
+	}, nil
+}
+
+func (r Registers) String() string {
+	return fmt.Sprintf("config: %v, rtd: %#04x (fault: %v), high threshold: %#04x, low threshold: %#04x, fault status: %v",
+		r.Config, r.RTD, r.RTDFault, r.HighThreshold, r.LowThreshold, r.FaultStatus)
+}
+
+// VBias is true, when bias voltage is on
+func (c ConfigRegister) VBias() bool {
+	return c&(1<<vBias) != 0
+}
+
+// Continuous is true, when automatic conversion mode is on
+func (c ConfigRegister) Continuous() bool {
+	return c&(1<<continuous) != 0
+}
+
+// OneShot is true, while single conversion is pending
+func (c ConfigRegister) OneShot() bool {
+	return c&(1<<oneShot) != 0
+}
+
+// ThreeWire is true for 3-wire RTD, 2-wire and 4-wire are configured the same way
+func (c ConfigRegister) ThreeWire() bool {
+	return c&(1<<wire3) != 0
+}
+
+// FaultDetection returns D3:D2 bits, non-zero value means fault detection is running
+func (c ConfigRegister) FaultDetection() uint8 {
+	return uint8(c>>faultDetect1) & 0x3
+}
+
+// Filter returns notch frequency of input filter
+func (c ConfigRegister) Filter() Filter {
+	if c&(1<<filter50Hz) != 0 {
+		return Filter50Hz
+	}
+	return Filter60Hz
+}
+
+func (c ConfigRegister) String() string {
+	var flags []string
+	if c.VBias() {
+		flags = append(flags, "VBIAS")
+	}
+	if c.Continuous() {
+		flags = append(flags, "continuous")
+	}
+	if c.OneShot() {
+		flags = append(flags, "one-shot")
+	}
+	if c.ThreeWire() {
+		flags = append(flags, "3-wire")
+	} else {
+		flags = append(flags, "2/4-wire")
+	}
+	if c.FaultDetection() != 0 {
+		flags = append(flags, fmt.Sprintf("fault detection %02b", c.FaultDetection()))
+	}
+	flags = append(flags, fmt.Sprintf("%dHz filter", c.Filter()))
+	return fmt.Sprintf("%#02x [%v]", uint8(c), strings.Join(flags, ", "))
+}
@@ -0,0 +1,117 @@
+package max31865_test
+
+import (
+	"github.com/a-clap/iot/pkg/max31865"
+	"github.com/a-clap/iot/pkg/max31865/emulator"
+	"github.com/stretchr/testify/require"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestSensor_Resistance(t *testing.T) {
+	r := require.New(t)
+	dev := emulator.New()
+	s, err := max31865.New(dev, max31865.RefRes(430.0))
+	r.Nil(err)
+
+	dev.SetResistance(215)
+	code, err := s.RTDCode()
+	r.Nil(err)
+	r.EqualValues(0x4000, code)
+
+	res, err := s.Resistance()
+	r.Nil(err)
+	r.InDelta(215, res, 0.001)
+
+	dev.SetFault(emulator.OpenRTD)
+	_, err = s.Resistance()
+	r.ErrorIs(err, max31865.ErrRtd)
+	_, err = s.RTDCode()
+	r.ErrorIs(err, max31865.ErrRtd)
+}
+
+func TestSensor_Registers(t *testing.T) {
+	r := require.New(t)
+	dev := emulator.New()
+	s, err := max31865.New(dev, max31865.RefRes(430.0), max31865.HighThreshold(100))
+	r.Nil(err)
+
+	dev.SetResistance(110)
+	regs, err := s.Registers()
+	r.Nil(err)
+	r.EqualValues(0xD1, regs.Config)
+	r.True(regs.Config.VBias())
+	r.True(regs.Config.Continuous())
+	r.False(regs.Config.OneShot())
+	r.True(regs.Config.ThreeWire())
+	r.Zero(regs.Config.FaultDetection())
+	r.Equal(max31865.Filter50Hz, regs.Config.Filter())
+	r.EqualValues(0x20BE, regs.RTD)
+	r.False(regs.RTDFault)
+	// 138.51Ω at 100°C
+	r.InDelta(138.51/430*32768, regs.HighThreshold, 1)
+	r.Zero(regs.LowThreshold)
+	r.True(regs.FaultStatus.OK())
+	r.Equal("config: 0xd1 [VBIAS, continuous, 3-wire, 50Hz filter], rtd: 0x20be (fault: false), "+
+		"high threshold: 0x293b, low threshold: 0x0000, fault status: no fault", regs.String())
+
+	// Registers doesn't clear faults
+	dev.SetFault(emulator.OpenRTD)
+	for i := 0; i < 2; i++ {
+		regs, err = s.Registers()
+		r.Nil(err)
+		r.True(regs.RTDFault)
+		r.Equal(max31865.FaultRTDHigh, regs.FaultStatus)
+	}
+}
+
+func TestSensor_ConfigRegister(t *testing.T) {
+	r := require.New(t)
+	c := max31865.ConfigRegister(0x2C)
+	r.False(c.VBias())
+	r.True(c.OneShot())
+	r.False(c.ThreeWire())
+	r.EqualValues(0b11, c.FaultDetection())
+	r.Equal(max31865.Filter60Hz, c.Filter())
+	r.Equal("0x2c [one-shot, 2/4-wire, fault detection 11, 60Hz filter]", c.String())
+}
+
+func TestSensor_PollResistance(t *testing.T) {
+	r := require.New(t)
+	dev := emulator.New()
+	dev.SetResistance(215)
+	s, err := max31865.New(dev, max31865.RefRes(430.0))
+	r.Nil(err)
+
+	data := make(chan max31865.Readings)
+	r.Nil(s.Poll(data, 5*time.Millisecond))
+	select {
+	case reading := <-data:
+		_, _, err := reading.Get()
+		r.Nil(err)
+		res, err := strconv.ParseFloat(reading.Resistance(), 32)
+		r.Nil(err)
+		r.InDelta(215, res, 0.001)
+	case <-time.After(100 * time.Millisecond):
+		r.Fail("waiting for readings too long")
+	}
+	r.Nil(s.Close())
+}
+
+func TestSensor_ConfigReadback(t *testing.T) {
+	r := require.New(t)
+	m := new(SensorTransferMock)
+	m.On("ReadWrite", maxInitCall).Return(maxPORState, nil).Once()
+	m.On("ReadWrite", []byte{0x80, 0xd1}).Return([]byte{0x00, 0x00}, nil).Once()
+	// e.g. wrong SPI mode shifts bits
+	m.On("ReadWrite", configReadback).Return([]byte{0x00, 0xa2}, nil).Once()
+
+	_, err := max31865.New(m)
+	r.ErrorIs(err, max31865.ErrConfig)
+	var configErr *max31865.ConfigError
+	r.ErrorAs(err, &configErr)
+	r.EqualValues(0xd1, configErr.Written)
+	r.EqualValues(0xa2, configErr.Read)
+	m.AssertExpectations(t)
+}
@@ -52,3 +52,72 @@ func Test_rtd(t *testing.T) {
 		})
 	}
 }
+
+func Test_toRtd(t *testing.T) {
+	// Codes from datasheet table, PT100 with 400Ω reference resistor
+	tests := []struct {
+		tmp float32
+		rtd uint16
+	}{
+		{tmp: -200, rtd: 0x0BDA >> 1},
+		{tmp: -50, rtd: 0x3366 >> 1},
+		{tmp: 0, rtd: 0x4000 >> 1},
+		{tmp: 70, rtd: 0x5154 >> 1},
+		{tmp: 1000, rtd: rtdMax},
+		{tmp: -300, rtd: 0},
+	}
+	cfg := newConfig()
+	cfg.refRes = 400
+	cfg.complete()
+	for _, tt := range tests {
+		got := cfg.toRtd(tt.tmp)
+		require.InDelta(t, tt.rtd, got, 2, tt.tmp)
+	}
+
+	cfg.refRes = 430
+	for tmp := float32(-200); tmp <= 800; tmp += 25 {
+		rtd := cfg.toRtd(tmp)
+		require.InDelta(t, tmp, cfg.toTemperature(rtd), 0.05, tmp)
+	}
+}
+
+func Test_toTemperatureNegative(t *testing.T) {
+	// PT100 resistances from IEC 60751 table below 0°C convert back to their temperatures within 0.1°C
+	tests := []struct {
+		res float64
+		tmp float32
+	}{
+		{res: 18.52, tmp: -200},
+		{res: 39.72, tmp: -150},
+		{res: 60.26, tmp: -100},
+		{res: 80.31, tmp: -50},
+		{res: 100, tmp: 0},
+	}
+	cfg := newConfig()
+	cfg.refRes = 400
+	cfg.complete()
+	for _, tt := range tests {
+		rtd := resistanceToRtd(tt.res, cfg.refRes)
+		require.InDelta(t, tt.tmp, cfg.toTemperature(rtd), 0.1, tt.tmp)
+	}
+}
+
+func Test_leadResistance(t *testing.T) {
+	cfg := newConfig()
+	cfg.refRes = 430
+	cfg.leadRes = 1.2
+	cfg.complete()
+
+	// 100Ω of RTD measured together with 1.2Ω of leads
+	rtd := resistanceToRtd(101.2, cfg.refRes)
+	for _, w := range []Wiring{ThreeWire, FourWire} {
+		cfg.wiring = w
+		require.InDelta(t, 101.2, cfg.resistance(rtd), 0.01, w)
+		require.InDelta(t, 3.07, cfg.toTemperature(rtd), 0.05, w)
+	}
+
+	cfg.wiring = TwoWire
+	require.InDelta(t, 100, cfg.resistance(rtd), 0.01)
+	require.InDelta(t, 0, cfg.toTemperature(rtd), 0.05)
+	require.Equal(t, rtd, cfg.toRtd(0))
+}
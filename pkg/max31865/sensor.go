@@ -1,7 +1,6 @@
 package max31865
 
 import (
-	"fmt"
 	"io"
 	"strconv"
 	"time"
@@ -12,6 +11,12 @@ type Sensor interface {
 	ID() string
 	Temperature() (float32, error)
 	Poll(data chan Readings, pollTime time.Duration) (err error)
+	RunFaultDetection(mode DetectionMode) (FaultStatus, error)
+	SetThresholds(low, high float32) error
+	Thresholds() (low, high float32, err error)
+	Resistance() (float32, error)
+	RTDCode() (uint16, error)
+	Registers() (Registers, error)
 }
 
 var _ Sensor = &sensor{}
@@ -26,17 +31,23 @@ type sensor struct {
 	fin    chan struct{}
 	stop   chan struct{}
 	data   chan Readings
+	state  State
 }
 
 type Readings interface {
 	ID() string
 	Get() (temperature string, timestamp time.Time, err error)
+	// Resistance returns measured resistance of RTD in Ω, it is empty on error
+	Resistance() string
+	// State returns health of sensor after this reading
+	State() State
 }
 
 type readings struct {
-	id, temperature string
-	timestamp       time.Time
-	err             error
+	id, temperature, resistance string
+	timestamp                   time.Time
+	err                         error
+	state                       State
 }
 
 func (s *sensor) Poll(data chan Readings, pollTime time.Duration) (err error) {
@@ -46,8 +57,10 @@ func (s *sensor) Poll(data chan Readings, pollTime time.Duration) (err error) {
 
 	s.cfg.polling.Store(true)
 	if pollTime == -1 {
+		s.cfg.pollType = pollAsync
 		err = s.prepareAsyncPoll()
 	} else {
+		s.cfg.pollType = pollSync
 		err = s.prepareSyncPoll(pollTime)
 	}
 
@@ -59,6 +72,7 @@ func (s *sensor) Poll(data chan Readings, pollTime time.Duration) (err error) {
 	s.fin = make(chan struct{})
 	s.stop = make(chan struct{})
 	s.data = data
+	s.state = Healthy
 	go s.poll()
 
 	return nil
@@ -83,6 +97,10 @@ func (s *sensor) prepareAsyncPoll() error {
 	if s.cfg.ready == nil {
 		return ErrNoReadyInterface
 	}
+	if s.cfg.mode == OneShot {
+		// Nothing would start conversion
+		return ErrOneShotAsync
+	}
 	s.trig = make(chan struct{}, 1)
 	return s.cfg.ready.Open(callback, s)
 }
@@ -93,23 +111,39 @@ func (s *sensor) poll() {
 		case <-s.stop:
 			s.cfg.polling.Store(false)
 		case <-s.trig:
-			tmp, err := s.Temperature()
+			code, err := s.RTDCode()
 			r := readings{
 				id:  s.ID(),
 				err: nil,
 			}
 			if err != nil {
 				r.err = err
+				s.tryRecover(err)
 			} else {
-				r.temperature = strconv.FormatFloat(float64(tmp), 'f', -1, 32)
+				r.temperature = strconv.FormatFloat(float64(s.cfg.toTemperature(code)), 'f', -1, 32)
+				r.resistance = strconv.FormatFloat(s.cfg.resistance(code), 'f', -1, 32)
 				r.timestamp = time.Now()
+				s.setState(Healthy, nil)
+			}
+			if !s.cfg.polling.Load() {
+				// Stopped during recovery
+				break
+			}
+			r.state = s.state
+			select {
+			case s.data <- r:
+			case <-s.stop:
+				// Nobody reads data, while sensor is being closed
+				s.cfg.polling.Store(false)
 			}
-			s.data <- r
 		}
 	}
 	// For sure there won't be more data
 	close(s.data)
-	if s.cfg.pollType == async {
+	if s.cfg.events != nil {
+		close(s.cfg.events)
+	}
+	if s.cfg.pollType == pollAsync {
 		s.cfg.ready.Close()
 		close(s.trig)
 	}
@@ -134,7 +168,30 @@ func callback(args any) error {
 }
 
 func (s *sensor) Temperature() (tmp float32, err error) {
-	r, err := s.read(regConf, regFault+1)
+	rtd, err := s.RTDCode()
+	if err != nil {
+		return
+	}
+	return s.cfg.toTemperature(rtd), nil
+}
+
+// Resistance measures resistance of RTD in Ω, LeadResistance is already subtracted
+func (s *sensor) Resistance() (float32, error) {
+	rtd, err := s.RTDCode()
+	if err != nil {
+		return 0, err
+	}
+	return float32(s.cfg.resistance(rtd)), nil
+}
+
+// RTDCode measures raw 15-bit RTD code, which is a ratio of RTD resistance to reference resistance
+func (s *sensor) RTDCode() (rtd uint16, err error) {
+	var r []byte
+	if s.cfg.mode == OneShot {
+		r, err = s.oneShot()
+	} else {
+		r, err = s.read(regConf, regFault+1)
+	}
 	if err != nil {
 		//	can't do much about it
 		return
@@ -144,11 +201,10 @@ func (s *sensor) Temperature() (tmp float32, err error) {
 		// Not handling error here, should have happened on previous call
 		_ = s.clearFaults()
 		// make error more specific
-		err = fmt.Errorf("%w: errorReg: %v, posibble causes: %v", err, r[regFault], errorCauses(r[regFault], s.cfg.wiring))
+		err = &Fault{Status: FaultStatus(r[regFault]), Wiring: s.cfg.wiring}
 		return
 	}
-	rtd := s.r.rtd()
-	return rtdToTemperature(rtd, s.cfg.refRes, s.cfg.rNominal), nil
+	return s.r.rtd(), nil
 }
 
 func (s *sensor) Close() error {
@@ -199,12 +255,35 @@ func (s *sensor) parse(args ...any) {
 		case Wiring:
 			s.cfg.wiring = arg
 			s.regCfg.setWiring(arg)
+		case Filter:
+			s.cfg.filter = arg
+			s.regCfg.setFilter(arg)
+		case Mode:
+			s.cfg.mode = arg
+			s.regCfg.setMode(arg)
 		case RefRes:
 			s.cfg.refRes = arg
 		case RNominal:
 			s.cfg.rNominal = arg
+		case LeadResistance:
+			s.cfg.leadRes = arg
+		case ManualDelay:
+			s.cfg.manualDelay = arg
+		case Curve:
+			s.cfg.curve = arg
+		case Conversion:
+			s.cfg.conversion = arg
+		case Recovery:
+			s.cfg.recovery = arg
+		case StateEvents:
+			s.cfg.events = arg
+		case HighThreshold:
+			s.cfg.highThreshold = &arg
+		case LowThreshold:
+			s.cfg.lowThreshold = &arg
 		}
 	}
+	s.cfg.complete()
 }
 
 func (s *sensor) clearFaults() error {
@@ -212,8 +291,28 @@ func (s *sensor) clearFaults() error {
 }
 
 func (s *sensor) config() error {
-	err := s.write(regConf, []byte{s.regCfg.reg()})
-	return err
+	if err := s.write(regConf, []byte{s.regCfg.reg()}); err != nil {
+		return err
+	}
+	if err := s.verifyConfig(); err != nil {
+		return err
+	}
+	if s.cfg.lowThreshold == nil && s.cfg.highThreshold == nil {
+		return nil
+	}
+	return s.writeThresholds(s.cfg.lowThreshold, s.cfg.highThreshold)
+}
+
+// verifyConfig reads back configuration register, mismatch means e.g. wrong SPI mode or bad wiring
+func (s *sensor) verifyConfig() error {
+	r, err := s.read(regConf, 1)
+	if err != nil {
+		return err
+	}
+	if r[0] != s.regCfg.reg() {
+		return &ConfigError{Written: ConfigRegister(s.regCfg.reg()), Read: ConfigRegister(r[0])}
+	}
+	return nil
 }
 
 func (s *sensor) read(addr byte, len int) ([]byte, error) {
@@ -242,3 +341,11 @@ func (r readings) ID() string {
 func (r readings) Get() (temperature string, timestamp time.Time, err error) {
 	return r.temperature, r.timestamp, r.err
 }
+
+func (r readings) Resistance() string {
+	return r.resistance
+}
+
+func (r readings) State() State {
+	return r.state
+}
@@ -39,6 +39,8 @@ var (
 	triggerMock *SensorTriggerMock
 	maxInitCall = []byte{0x0, 0x0, 0x0, 0x0, 0x0, 0x0, 0x0, 0x0, 0x0}
 	maxPORState = []byte{0x0, 0x0, 0x0, 0x0, 0xFF, 0xFF, 0x0, 0x0, 0x0}
+	// configReadback reads configuration register, after it is written
+	configReadback = []byte{0x0, 0x0}
 )
 
 func TestMaxSensor(t *testing.T) {
@@ -83,6 +85,7 @@ func (s *SensorSuite) TestNew() {
 		sensorMock.On("ReadWrite", maxInitCall).Return(maxPORState, nil)
 		// Configuration call
 		sensorMock.On("ReadWrite", arg.call).Return(arg.returnArgs, nil)
+		sensorMock.On("ReadWrite", configReadback).Return([]byte{0x00, arg.call[1]}, nil).Once()
 		max, _ := max31865.New(sensorMock, arg.newArgs...)
 		s.NotNil(max)
 	}
@@ -127,6 +130,7 @@ func (s *SensorSuite) TestTemperature() {
 		sensorMock.On("ReadWrite", maxInitCall).Return(maxPORState, nil).Once()
 		// Configuration call
 		sensorMock.On("ReadWrite", []byte{0x80, 0xd1}).Return([]byte{0x00, 0x00}, nil)
+		sensorMock.On("ReadWrite", configReadback).Return([]byte{0x00, 0xd1}, nil).Once()
 		max, _ := max31865.New(sensorMock, max31865.RefRes(400.0))
 		s.NotNil(max)
 
@@ -142,6 +146,7 @@ func (s *SensorSuite) TestTemperatureError() {
 	sensorMock.On("ReadWrite", maxInitCall).Return(maxPORState, nil).Once()
 	// Configuration call
 	sensorMock.On("ReadWrite", []byte{0x80, 0xd1}).Return([]byte{0x00, 0x00}, nil)
+	sensorMock.On("ReadWrite", configReadback).Return([]byte{0x00, 0xd1}, nil).Once()
 	max, _ := max31865.New(sensorMock, max31865.RefRes(400.0))
 	s.NotNil(max)
 
@@ -160,6 +165,7 @@ func (s *SensorSuite) TestPollErrors() {
 	sensorMock.On("ReadWrite", maxInitCall).Return(maxPORState, nil).Once()
 	// Configuration call
 	sensorMock.On("ReadWrite", []byte{0x80, 0xd1}).Return([]byte{0x00, 0x00}, nil)
+	sensorMock.On("ReadWrite", configReadback).Return([]byte{0x00, 0xd1}, nil).Once()
 	max, _ := max31865.New(sensorMock, max31865.RefRes(400.0))
 	s.NotNil(max)
 
@@ -176,6 +182,7 @@ func (s *SensorSuite) TestPollTime() {
 	sensorMock.On("ReadWrite", maxInitCall).Return(maxPORState, nil).Once()
 	// Configuration call
 	sensorMock.On("ReadWrite", []byte{0x80, 0xd1}).Return([]byte{0x00, 0x00}, nil)
+	sensorMock.On("ReadWrite", configReadback).Return([]byte{0x00, 0xd1}, nil).Once()
 	id := max31865.ID("max")
 	max, _ := max31865.New(sensorMock, max31865.RefRes(400.0), id)
 	s.NotNil(max)
@@ -243,6 +250,7 @@ func (s *SensorSuite) TestPollTwice() {
 	sensorMock.On("ReadWrite", maxInitCall).Return(maxPORState, nil).Once()
 	// Configuration call
 	sensorMock.On("ReadWrite", []byte{0x80, 0xd1}).Return([]byte{0x00, 0x00}, nil)
+	sensorMock.On("ReadWrite", configReadback).Return([]byte{0x00, 0xd1}, nil).Once()
 	max, _ := max31865.New(sensorMock, max31865.RefRes(400.0))
 	s.NotNil(max)
 
@@ -277,6 +285,7 @@ func (s *SensorSuite) TestPollTriggerReturnsCorrectErrors() {
 	sensorMock.On("ReadWrite", maxInitCall).Return(maxPORState, nil).Once()
 	// Configuration call
 	sensorMock.On("ReadWrite", []byte{0x80, 0xd1}).Return([]byte{0x00, 0x00}, nil).Once()
+	sensorMock.On("ReadWrite", configReadback).Return([]byte{0x00, 0xd1}, nil).Once()
 	max, _ := max31865.New(sensorMock, max31865.RefRes(400.0), triggerMock)
 	s.NotNil(max)
 
@@ -290,6 +299,7 @@ func (s *SensorSuite) TestPollTriggerReturnsCorrectErrors() {
 	{
 		sensorMock.On("ReadWrite", maxInitCall).Return(maxPORState, nil).Twice()
 		sensorMock.On("ReadWrite", []byte{0x80, 0xd1}).Return([]byte{0x00, 0x00}, nil).Once()
+		sensorMock.On("ReadWrite", configReadback).Return([]byte{0x00, 0xd1}, nil).Once()
 		max, _ := max31865.New(sensorMock, max31865.RefRes(400.0), triggerMock)
 
 		triggerMock.On("Open", mock.Anything).Return(nil).Once()
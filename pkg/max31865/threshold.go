@@ -0,0 +1,58 @@
+package max31865
+
+import (
+	"fmt"
+)
+
+// HighThreshold is a temperature in °C, above which MAX31865 sets RTD high fault
+type HighThreshold float32
+
+// LowThreshold is a temperature in °C, below which MAX31865 sets RTD low fault
+type LowThreshold float32
+
+// rtdMax is the biggest 15-bit RTD code
+const rtdMax = 0x7FFF
+
+// SetThresholds writes fault thresholds, so sensor flags RTD out of range by itself
+func (s *sensor) SetThresholds(low, high float32) error {
+	if low > high {
+		return fmt.Errorf("%w: low: %v, high: %v", ErrThreshold, low, high)
+	}
+	l, h := LowThreshold(low), HighThreshold(high)
+	if err := s.writeThresholds(&l, &h); err != nil {
+		return err
+	}
+	// Kept only when chip accepted them, so config() after recovery restores the same values
+	s.cfg.lowThreshold, s.cfg.highThreshold = &l, &h
+	return nil
+}
+
+// Thresholds reads back fault thresholds from sensor, in °C
+func (s *sensor) Thresholds() (low, high float32, err error) {
+	r, err := s.read(regHFaultMsb, regLFaultLsb-regHFaultMsb+1)
+	if err != nil {
+		return 0, 0, err
+	}
+	toTemperature := func(msb, lsb byte) float32 {
+		code := (uint16(msb)<<8 | uint16(lsb)) >> 1
+		return s.cfg.toTemperature(code)
+	}
+	high = toTemperature(r[0], r[1])
+	low = toTemperature(r[2], r[3])
+	return low, high, nil
+}
+
+func (s *sensor) writeThresholds(lowThreshold *LowThreshold, highThreshold *HighThreshold) error {
+	low, high := uint16(0), uint16(rtdMax)
+	if lowThreshold != nil {
+		low = s.cfg.toRtd(float32(*lowThreshold))
+	}
+	if highThreshold != nil {
+		high = s.cfg.toRtd(float32(*highThreshold))
+	}
+	if low > high {
+		return fmt.Errorf("%w: low rtd: %v, high rtd: %v", ErrThreshold, low, high)
+	}
+	low, high = low<<1, high<<1
+	return s.write(regHFaultMsb, []byte{byte(high >> 8), byte(high), byte(low >> 8), byte(low)})
+}
@@ -0,0 +1,95 @@
+package max31865_test
+
+import (
+	"errors"
+	"github.com/a-clap/iot/pkg/max31865"
+	"github.com/a-clap/iot/pkg/max31865/emulator"
+	"github.com/stretchr/testify/require"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestSensor_Thresholds(t *testing.T) {
+	r := require.New(t)
+	m := new(SensorTransferMock)
+	m.On("ReadWrite", maxInitCall).Return(maxPORState, nil).Once()
+	m.On("ReadWrite", []byte{0x80, 0xd1}).Return([]byte{0x00, 0x00}, nil).Once()
+	m.On("ReadWrite", configReadback).Return([]byte{0x00, 0xd1}, nil).Once()
+	// Only high threshold passed, low stays at chip default
+	m.On("ReadWrite", []byte{0x83, 0x51, 0x54, 0x00, 0x00}).Return(make([]byte, 5), nil).Once()
+	max, err := max31865.New(m, max31865.RefRes(400), max31865.HighThreshold(70))
+	r.Nil(err)
+
+	m.On("ReadWrite", []byte{0x83, 0x40, 0x00, 0x33, 0x66}).Return(make([]byte, 5), nil).Once()
+	r.Nil(max.SetThresholds(-50, 0))
+
+	m.On("ReadWrite", []byte{0x03, 0x00, 0x00, 0x00, 0x00}).Return([]byte{0x00, 0x40, 0x00, 0x33, 0x66}, nil).Once()
+	low, high, err := max.Thresholds()
+	r.Nil(err)
+	r.InDelta(-50, low, 0.1)
+	r.InDelta(0, high, 0.1)
+
+	r.ErrorIs(max.SetThresholds(10, 0), max31865.ErrThreshold)
+	m.AssertExpectations(t)
+}
+
+func TestNew_ThresholdsInverted(t *testing.T) {
+	m := new(SensorTransferMock)
+	m.On("ReadWrite", maxInitCall).Return(maxPORState, nil).Once()
+	m.On("ReadWrite", []byte{0x80, 0xd1}).Return([]byte{0x00, 0x00}, nil).Once()
+	m.On("ReadWrite", configReadback).Return([]byte{0x00, 0xd1}, nil).Once()
+	_, err := max31865.New(m, max31865.LowThreshold(100), max31865.HighThreshold(50))
+	require.ErrorIs(t, err, max31865.ErrThreshold)
+}
+
+// thresholdTransfer fails writes of thresholds on demand and remembers the last accepted one
+type thresholdTransfer struct {
+	*emulator.Device
+	mtx      sync.Mutex
+	fail     bool
+	accepted []byte
+}
+
+func (t *thresholdTransfer) ReadWrite(write []byte) ([]byte, error) {
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+	if write[0] == 0x83 {
+		if t.fail {
+			return nil, errors.New("spi")
+		}
+		t.accepted = append([]byte(nil), write...)
+	}
+	return t.Device.ReadWrite(write)
+}
+
+func (t *thresholdTransfer) last() []byte {
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+	return t.accepted
+}
+
+func TestSensor_ThresholdsRejected(t *testing.T) {
+	r := require.New(t)
+	tr := &thresholdTransfer{Device: emulator.New()}
+	events := make(max31865.StateEvents)
+	s, err := max31865.New(tr, max31865.RefRes(430.0), max31865.HighThreshold(70),
+		max31865.Recovery{Retries: 3, Backoff: time.Millisecond}, events)
+	r.Nil(err)
+	initial := tr.last()
+	r.NotNil(initial)
+
+	tr.fail = true
+	r.NotNil(s.SetThresholds(-50, 0))
+	tr.fail = false
+
+	// Recovery writes configuration again, it must be the one chip accepted
+	data := make(chan max31865.Readings)
+	r.Nil(s.Poll(data, 5*time.Millisecond))
+	tr.Device.SetFault(emulator.OpenRTD)
+	r.Equal(max31865.Degraded, nextEvent(t, data, events).To)
+	tr.Device.SetFault(emulator.NoFault)
+	r.Equal(max31865.Healthy, nextEvent(t, data, events).To)
+	r.Equal(initial, tr.last())
+	r.Nil(s.Close())
+}